@@ -0,0 +1,96 @@
+// Package apierror writes API errors as a JSON envelope:
+//
+//	{"code": "validation_failed", "message": "...", "fields": {"email": "..."}}
+//
+// Code is stable and meant for programs; Message is for people and may
+// change. Fields is only present for per-field validation failures.
+package apierror
+
+import (
+	"encoding/json"
+	"event-registration-backend/requestid"
+	"log"
+	"net/http"
+)
+
+// Error codes.
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeValidationFailed    = "validation_failed"
+	CodeEmailTaken          = "email_taken"
+	CodeInvalidCredentials  = "invalid_credentials"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeConflict            = "conflict"
+	CodeEventFull           = "event_full"
+	CodeVerificationExpired = "verification_expired"
+	CodeRequestTooLarge     = "request_too_large"
+	CodeRateLimited         = "rate_limited"
+	CodeChallengeFailed     = "challenge_failed"
+	CodeUnavailable         = "unavailable"
+	CodeTimeout             = "timeout"
+	CodeImportIncomplete    = "import_incomplete"
+	CodeRestoreIncomplete   = "restore_incomplete"
+	CodeInternal            = "internal_error"
+)
+
+// Envelope is the body of every error response.
+type Envelope struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	// RequestID is set on internal errors so a report can be matched to
+	// the server log.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// Write replies with status and an error envelope.
+func Write(w http.ResponseWriter, status int, code, message string) {
+	WriteFields(w, status, code, message, nil)
+}
+
+// WriteFields replies with status and an error envelope that lists what is
+// wrong with each field, keyed by the field's JSON name.
+func WriteFields(w http.ResponseWriter, status int, code, message string, fields map[string]string) {
+	writeEnvelope(w, status, Envelope{Code: code, Message: message, Fields: fields})
+}
+
+// Internal logs err with the request's correlation ID and replies 500 with
+// a generic message and that ID. The error text never reaches the client,
+// since storage errors can reveal project names, paths and queries.
+func Internal(w http.ResponseWriter, r *http.Request, what string, err error) {
+	id := requestid.FromContext(r.Context())
+	if id == "" {
+		id = requestid.New()
+		w.Header().Set(requestid.Header, id)
+	}
+	log.Printf("request %s: %s %s: %s: %v", id, r.Method, r.URL.Path, what, err)
+	writeEnvelope(w, http.StatusInternalServerError, Envelope{
+		Code:      CodeInternal,
+		Message:   "Something went wrong on our side. Please try again later.",
+		RequestID: id,
+	})
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, body Envelope) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Del("Content-Disposition")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// NotFound replies with a not_found error. It can be used as a router's
+// NotFoundHandler.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, http.StatusNotFound, CodeNotFound, "Not found")
+}
+
+// MethodNotAllowed replies with a method_not_allowed error.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Write(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed")
+}
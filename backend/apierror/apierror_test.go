@@ -0,0 +1,40 @@
+package apierror_test
+
+import (
+	"encoding/json"
+	"event-registration-backend/apierror"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFields(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Disposition", `attachment; filename="x.csv"`)
+
+	apierror.WriteFields(w, http.StatusBadRequest, apierror.CodeValidationFailed, "Invalid registration",
+		map[string]string{"email": "Email is required"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+
+	var body apierror.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, apierror.Envelope{
+		Code:    "validation_failed",
+		Message: "Invalid registration",
+		Fields:  map[string]string{"email": "Email is required"},
+	}, body)
+}
+
+func TestWrite_OmitsFields(t *testing.T) {
+	w := httptest.NewRecorder()
+	apierror.Write(w, http.StatusConflict, apierror.CodeEmailTaken, "Email already registered")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"code":"email_taken","message":"Email already registered"}`, w.Body.String())
+}
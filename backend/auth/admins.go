@@ -0,0 +1,106 @@
+// Package auth manages named admin accounts stored alongside the event.
+package auth
+
+import (
+	"context"
+	"errors"
+	"event-registration-backend/models"
+	"fmt"
+	"regexp"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MinPasswordLength is the shortest password accepted for a new admin.
+const MinPasswordLength = 12
+
+var (
+	// ErrInvalidCredentials is returned for an unknown user or wrong
+	// password; the two are deliberately indistinguishable.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrAdminExists is returned when creating an admin whose username is
+	// taken.
+	ErrAdminExists = errors.New("admin already exists")
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,63}$`)
+
+// ValidateUsername checks an admin username: lowercase letters, digits,
+// '.', '_' and '-', 3 to 64 characters long.
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return errors.New("username must be 3-64 lowercase letters, digits, '.', '_' or '-'")
+	}
+	return nil
+}
+
+// ValidatePassword checks an admin password is long enough.
+func ValidatePassword(password string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	return nil
+}
+
+// HashPassword returns the bcrypt hash stored for an admin password.
+func HashPassword(password string) (string, error) {
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches hash.
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// CreateAdmin stores a new admin in admins. Usernames are lowercase
+// letters, digits, '.', '_' and '-', 3 to 64 characters long.
+func CreateAdmin(ctx context.Context, admins *firestore.CollectionRef, username, password string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = admins.Doc(username).Create(ctx, models.AdminUser{
+		Username:     username,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrAdminExists
+	}
+	return err
+}
+
+// VerifyAdmin checks a username and password against admins.
+func VerifyAdmin(ctx context.Context, admins *firestore.CollectionRef, username, password string) error {
+	doc, err := admins.Doc(username).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return ErrInvalidCredentials
+	}
+	if err != nil {
+		return err
+	}
+
+	var admin models.AdminUser
+	if err := doc.DataTo(&admin); err != nil {
+		return err
+	}
+	if !CheckPassword(admin.PasswordHash, password) {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
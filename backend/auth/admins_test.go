@@ -0,0 +1,23 @@
+package auth_test
+
+import (
+	"event-registration-backend/auth"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse battery")
+	require.NoError(t, err)
+
+	assert.NotContains(t, hash, "correct horse")
+	assert.True(t, auth.CheckPassword(hash, "correct horse battery"))
+	assert.False(t, auth.CheckPassword(hash, "wrong horse battery"))
+}
+
+func TestHashPassword_TooShort(t *testing.T) {
+	_, err := auth.HashPassword("short")
+	assert.Error(t, err)
+}
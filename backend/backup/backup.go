@@ -0,0 +1,312 @@
+// Package backup snapshots an event's attendees, speakers, sessions,
+// settings and migration ledger to a versioned JSON archive and restores
+// archives into an empty event.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/migrations"
+	"event-registration-backend/models"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FormatVersion is written to every archive. Restore rejects archives with
+// a newer version than it understands. Version 2 added verification token
+// hashes, settings and the migration ledger.
+const FormatVersion = 2
+
+// Collection names, as stored under clients/{ClientID}.
+const (
+	CollectionAttendees = "attendees"
+	CollectionSpeakers  = "speakers"
+	CollectionSessions  = "sessions"
+	CollectionSettings  = "settings"
+	// CollectionMigrations is the migration ledger. Unlike the others it
+	// need not be empty before a restore, since a server that migrates on
+	// startup fills it in before anything can be restored.
+	CollectionMigrations = "migrations"
+)
+
+// AllCollections lists every collection an archive covers.
+var AllCollections = []string{CollectionAttendees, CollectionSpeakers, CollectionSessions, CollectionSettings, CollectionMigrations}
+
+var (
+	// ErrNotEmpty is returned when restoring into a collection that
+	// already has documents, or resuming into one that has documents the
+	// archive does not.
+	ErrNotEmpty = errors.New("target event is not empty")
+	// ErrUnknownCollection is returned for a collection name outside
+	// AllCollections.
+	ErrUnknownCollection = errors.New("unknown collection")
+)
+
+// Archive is the on-disk backup format. Document IDs are preserved so
+// session speaker references stay valid after a restore.
+type Archive struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"createdAt"`
+	ClientID  string           `json:"clientId"`
+	Attendees []AttendeeRecord `json:"attendees"`
+	Speakers  []models.Speaker `json:"speakers"`
+	Sessions  []models.Session `json:"sessions"`
+	Settings  Settings         `json:"settings"`
+	// Migrations records the migrations the archived documents have been
+	// through, so the ones they missed run after a restore.
+	Migrations []migrations.Record `json:"migrations"`
+}
+
+// AttendeeRecord is an attendee as archived. Unlike the API representation it
+// keeps the verification token hash, so links already emailed to pending
+// attendees still work after a restore.
+type AttendeeRecord struct {
+	models.Attendee
+	VerificationTokenHash string `json:"verificationTokenHash,omitempty"`
+}
+
+// Settings holds the event-wide settings documents.
+type Settings struct {
+	EmailDomains *models.EmailDomainPolicy `json:"emailDomains,omitempty"`
+}
+
+// Document is a single document to write, keyed by its ID.
+type Document struct {
+	ID   string
+	Data interface{}
+}
+
+// Store is the storage an archive is read from or restored into.
+type Store interface {
+	ListAttendees(ctx context.Context) ([]models.Attendee, error)
+	ListSpeakers(ctx context.Context) ([]models.Speaker, error)
+	ListSessions(ctx context.Context) ([]models.Session, error)
+	GetSettings(ctx context.Context) (Settings, error)
+	ListMigrations(ctx context.Context) ([]migrations.Record, error)
+	// IsEmpty reports whether collection has no documents.
+	IsEmpty(ctx context.Context, collection string) (bool, error)
+	// ListIDs returns the IDs of every document in collection.
+	ListIDs(ctx context.Context, collection string) ([]string, error)
+	// Write creates or replaces docs in collection and returns how many it
+	// wrote, which is fewer than len(docs) only when it fails partway.
+	Write(ctx context.Context, collection string, docs []Document) (int, error)
+	// Delete removes the documents with the given IDs from collection.
+	Delete(ctx context.Context, collection string, ids []string) error
+}
+
+// RestoreResult counts the documents written per collection. When a restore
+// fails it still counts what was written before the failure.
+type RestoreResult map[string]int
+
+// Create reads every collection from store into a new archive.
+func Create(ctx context.Context, store Store, clientID string) (*Archive, error) {
+	archive := &Archive{
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		ClientID:  clientID,
+	}
+
+	attendees, err := store.ListAttendees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading attendees: %w", err)
+	}
+	for _, a := range attendees {
+		archive.Attendees = append(archive.Attendees, AttendeeRecord{Attendee: a, VerificationTokenHash: a.VerificationTokenHash})
+	}
+	if archive.Speakers, err = store.ListSpeakers(ctx); err != nil {
+		return nil, fmt.Errorf("reading speakers: %w", err)
+	}
+	if archive.Sessions, err = store.ListSessions(ctx); err != nil {
+		return nil, fmt.Errorf("reading sessions: %w", err)
+	}
+	if archive.Settings, err = store.GetSettings(ctx); err != nil {
+		return nil, fmt.Errorf("reading settings: %w", err)
+	}
+	if archive.Migrations, err = store.ListMigrations(ctx); err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+	return archive, nil
+}
+
+// Write encodes archive as indented JSON.
+func Write(w io.Writer, archive *Archive) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(archive)
+}
+
+// Read decodes and checks an archive. Fields it does not know are refused,
+// since they would be dropped rather than restored.
+func Read(r io.Reader) (*Archive, error) {
+	var archive Archive
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&archive); err != nil {
+		return nil, fmt.Errorf("decoding archive: %w", err)
+	}
+	if archive.Version < 1 || archive.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+	for _, s := range archive.Speakers {
+		if s.ID == "" {
+			return nil, errors.New("archive has a speaker without an id")
+		}
+	}
+	for _, s := range archive.Sessions {
+		if s.ID == "" {
+			return nil, errors.New("archive has a session without an id")
+		}
+	}
+	for _, a := range archive.Attendees {
+		if a.ID == "" {
+			return nil, errors.New("archive has an attendee without an id")
+		}
+	}
+	for _, m := range archive.Migrations {
+		if m.ID == "" {
+			return nil, errors.New("archive has a migration without an id")
+		}
+	}
+	return &archive, nil
+}
+
+// Restore writes the given collections of archive into store. Every target
+// collection must be empty; pass only speakers and sessions to clone an
+// agenda into a new event without its attendees.
+//
+// The collections are written in batches, not atomically. If a restore
+// fails partway, the documents it wrote stay and the result counts them;
+// finish it with Resume.
+func Restore(ctx context.Context, store Store, archive *Archive, collections []string) (RestoreResult, error) {
+	return restore(ctx, store, archive, collections, false)
+}
+
+// Resume finishes a Restore of the same archive and collections that failed
+// partway. Instead of being empty, each target collection may only hold
+// documents from archive, which are written again.
+func Resume(ctx context.Context, store Store, archive *Archive, collections []string) (RestoreResult, error) {
+	return restore(ctx, store, archive, collections, true)
+}
+
+func restore(ctx context.Context, store Store, archive *Archive, collections []string, resume bool) (RestoreResult, error) {
+	if len(collections) == 0 {
+		collections = AllCollections
+	}
+
+	docs := make(map[string][]Document, len(collections))
+	for _, collection := range collections {
+		switch collection {
+		case CollectionAttendees:
+			for _, a := range archive.Attendees {
+				attendee := a.Attendee
+				attendee.VerificationTokenHash = a.VerificationTokenHash
+				docs[collection] = append(docs[collection], Document{ID: a.ID, Data: attendee})
+			}
+		case CollectionSpeakers:
+			for _, s := range archive.Speakers {
+				docs[collection] = append(docs[collection], Document{ID: s.ID, Data: s})
+			}
+		case CollectionSessions:
+			for _, s := range archive.Sessions {
+				docs[collection] = append(docs[collection], Document{ID: s.ID, Data: s})
+			}
+		case CollectionSettings:
+			if archive.Settings.EmailDomains != nil {
+				docs[collection] = append(docs[collection], Document{ID: models.EmailDomainPolicyID, Data: archive.Settings.EmailDomains})
+			}
+		case CollectionMigrations:
+			for _, m := range archive.Migrations {
+				docs[collection] = append(docs[collection], Document{ID: m.ID, Data: m})
+			}
+		default:
+			return nil, fmt.Errorf("%w %q", ErrUnknownCollection, collection)
+		}
+	}
+
+	for _, collection := range collections {
+		if collection == CollectionMigrations {
+			continue
+		}
+		var err error
+		if resume {
+			err = checkOnlyArchived(ctx, store, collection, docs[collection])
+		} else {
+			err = checkEmpty(ctx, store, collection)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(RestoreResult, len(collections))
+	for _, collection := range collections {
+		if collection == CollectionMigrations {
+			if err := clearLedger(ctx, store, archive.Migrations); err != nil {
+				return result, fmt.Errorf("writing %s: %w", collection, err)
+			}
+		}
+		n, err := store.Write(ctx, collection, docs[collection])
+		if n > 0 || err == nil {
+			result[collection] = n
+		}
+		if err != nil {
+			return result, fmt.Errorf("writing %s: %w", collection, err)
+		}
+	}
+	return result, nil
+}
+
+func checkEmpty(ctx context.Context, store Store, collection string) error {
+	empty, err := store.IsEmpty(ctx, collection)
+	if err != nil {
+		return fmt.Errorf("checking %s: %w", collection, err)
+	}
+	if !empty {
+		return fmt.Errorf("%w: %s already has documents", ErrNotEmpty, collection)
+	}
+	return nil
+}
+
+// checkOnlyArchived makes sure resuming a restore cannot overwrite documents
+// that did not come from the archive being restored.
+func checkOnlyArchived(ctx context.Context, store Store, collection string, archived []Document) error {
+	ids, err := store.ListIDs(ctx, collection)
+	if err != nil {
+		return fmt.Errorf("checking %s: %w", collection, err)
+	}
+	known := make(map[string]bool, len(archived))
+	for _, doc := range archived {
+		known[doc.ID] = true
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return fmt.Errorf("%w: %s has document %q, which is not in the archive", ErrNotEmpty, collection, id)
+		}
+	}
+	return nil
+}
+
+// clearLedger deletes the ledger records the archive does not have, so the
+// migrations its documents missed run again after the restore.
+func clearLedger(ctx context.Context, store Store, keep []migrations.Record) error {
+	current, err := store.ListMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	archived := make(map[string]bool, len(keep))
+	for _, m := range keep {
+		archived[m.ID] = true
+	}
+	var stale []string
+	for _, m := range current {
+		if !archived[m.ID] {
+			stale = append(stale, m.ID)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return store.Delete(ctx, CollectionMigrations, stale)
+}
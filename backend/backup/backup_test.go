@@ -0,0 +1,263 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"event-registration-backend/backup"
+	"event-registration-backend/migrations"
+	"event-registration-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory backup.Store.
+type memoryStore struct {
+	attendees  []models.Attendee
+	speakers   []models.Speaker
+	sessions   []models.Session
+	settings   backup.Settings
+	migrations []migrations.Record
+	written    map[string][]backup.Document
+	deleted    map[string][]string
+	// failWrite makes writing that collection fail after its first document.
+	failWrite string
+}
+
+func (m *memoryStore) ListAttendees(ctx context.Context) ([]models.Attendee, error) {
+	return m.attendees, nil
+}
+
+func (m *memoryStore) ListSpeakers(ctx context.Context) ([]models.Speaker, error) {
+	return m.speakers, nil
+}
+
+func (m *memoryStore) ListSessions(ctx context.Context) ([]models.Session, error) {
+	return m.sessions, nil
+}
+
+func (m *memoryStore) GetSettings(ctx context.Context) (backup.Settings, error) {
+	return m.settings, nil
+}
+
+func (m *memoryStore) ListMigrations(ctx context.Context) ([]migrations.Record, error) {
+	return m.migrations, nil
+}
+
+func (m *memoryStore) IsEmpty(ctx context.Context, collection string) (bool, error) {
+	if len(m.written[collection]) > 0 {
+		return false, nil
+	}
+	switch collection {
+	case backup.CollectionAttendees:
+		return len(m.attendees) == 0, nil
+	case backup.CollectionSpeakers:
+		return len(m.speakers) == 0, nil
+	case backup.CollectionSettings:
+		return m.settings.EmailDomains == nil, nil
+	case backup.CollectionMigrations:
+		return len(m.migrations) == 0, nil
+	default:
+		return len(m.sessions) == 0, nil
+	}
+}
+
+func (m *memoryStore) ListIDs(ctx context.Context, collection string) ([]string, error) {
+	var ids []string
+	switch collection {
+	case backup.CollectionAttendees:
+		for _, a := range m.attendees {
+			ids = append(ids, a.ID)
+		}
+	case backup.CollectionSpeakers:
+		for _, s := range m.speakers {
+			ids = append(ids, s.ID)
+		}
+	case backup.CollectionSessions:
+		for _, s := range m.sessions {
+			ids = append(ids, s.ID)
+		}
+	}
+	for _, doc := range m.written[collection] {
+		ids = append(ids, doc.ID)
+	}
+	return ids, nil
+}
+
+func (m *memoryStore) Write(ctx context.Context, collection string, docs []backup.Document) (int, error) {
+	if m.written == nil {
+		m.written = make(map[string][]backup.Document)
+	}
+	if collection == m.failWrite && len(docs) > 1 {
+		m.written[collection] = append(m.written[collection], docs[0])
+		return 1, errors.New("storage unavailable")
+	}
+	m.written[collection] = append(m.written[collection], docs...)
+	return len(docs), nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, collection string, ids []string) error {
+	if m.deleted == nil {
+		m.deleted = make(map[string][]string)
+	}
+	m.deleted[collection] = append(m.deleted[collection], ids...)
+	return nil
+}
+
+func sourceStore() *memoryStore {
+	return &memoryStore{
+		attendees: []models.Attendee{{ID: "a1", FullName: "Jane Doe", Email: "jane@example.com", CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		speakers:  []models.Speaker{{ID: "sp1", Name: "Ada Lovelace"}},
+		sessions:  []models.Session{{ID: "se1", Title: "Keynote", SpeakerID: "sp1"}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	archive, err := backup.Create(ctx, sourceStore(), "event-2025")
+	require.NoError(t, err)
+	assert.Equal(t, backup.FormatVersion, archive.Version)
+
+	var buf bytes.Buffer
+	require.NoError(t, backup.Write(&buf, archive))
+	decoded, err := backup.Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "event-2025", decoded.ClientID)
+	assert.True(t, archive.Attendees[0].CreatedAt.Equal(decoded.Attendees[0].CreatedAt))
+
+	target := &memoryStore{}
+	result, err := backup.Restore(ctx, target, decoded, nil)
+	require.NoError(t, err)
+	assert.Equal(t, backup.RestoreResult{"attendees": 1, "speakers": 1, "sessions": 1, "settings": 0, "migrations": 0}, result)
+	assert.Equal(t, "se1", target.written["sessions"][0].ID)
+	assert.Equal(t, "sp1", target.written["sessions"][0].Data.(models.Session).SpeakerID)
+}
+
+func TestRoundTrip_PendingAttendeeSettingsAndLedger(t *testing.T) {
+	ctx := context.Background()
+	hold := time.Date(2025, 1, 3, 3, 4, 5, 0, time.UTC)
+	appliedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := &memoryStore{
+		attendees: []models.Attendee{{
+			ID:                    "a2",
+			Email:                 "pending@example.com",
+			Status:                models.StatusPendingVerification,
+			HoldExpiresAt:         &hold,
+			VerificationTokenHash: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+		}},
+		settings:   backup.Settings{EmailDomains: &models.EmailDomainPolicy{Blocked: []string{"spam.example"}, Allowed: []string{}}},
+		migrations: []migrations.Record{{ID: "0001_schema_version", AppliedAt: appliedAt}},
+	}
+
+	archive, err := backup.Create(ctx, source, "event-2025")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, backup.Write(&buf, archive))
+	decoded, err := backup.Read(&buf)
+	require.NoError(t, err)
+
+	// The target server has already recorded a migration the archive
+	// predates, so it must run again over the restored documents
+	target := &memoryStore{migrations: []migrations.Record{{ID: "0001_schema_version"}, {ID: "0002_later"}}}
+	result, err := backup.Restore(ctx, target, decoded, nil)
+	require.NoError(t, err)
+	assert.Equal(t, backup.RestoreResult{"attendees": 1, "speakers": 0, "sessions": 0, "settings": 1, "migrations": 1}, result)
+
+	restored := target.written["attendees"][0].Data.(models.Attendee)
+	assert.Equal(t, source.attendees[0].VerificationTokenHash, restored.VerificationTokenHash, "pending links keep working")
+	assert.Equal(t, models.StatusPendingVerification, restored.Status)
+	require.NotNil(t, restored.HoldExpiresAt)
+	assert.True(t, hold.Equal(*restored.HoldExpiresAt))
+
+	require.Len(t, target.written["settings"], 1)
+	assert.Equal(t, models.EmailDomainPolicyID, target.written["settings"][0].ID)
+	assert.Equal(t, []string{"spam.example"}, target.written["settings"][0].Data.(*models.EmailDomainPolicy).Blocked)
+
+	assert.Equal(t, []string{"0002_later"}, target.deleted["migrations"])
+	require.Len(t, target.written["migrations"], 1)
+	assert.True(t, appliedAt.Equal(target.written["migrations"][0].Data.(migrations.Record).AppliedAt))
+}
+
+func TestRestore_AgendaOnly(t *testing.T) {
+	archive, err := backup.Create(context.Background(), sourceStore(), "event-2024")
+	require.NoError(t, err)
+
+	// The target already has attendees, which is fine when only the agenda
+	// is being cloned.
+	target := &memoryStore{attendees: []models.Attendee{{ID: "x"}}}
+	result, err := backup.Restore(context.Background(), target, archive, []string{"speakers", "sessions"})
+	require.NoError(t, err)
+	assert.Equal(t, backup.RestoreResult{"speakers": 1, "sessions": 1}, result)
+	assert.NotContains(t, target.written, "attendees")
+}
+
+func TestRestore_RefusesNonEmptyTarget(t *testing.T) {
+	archive, err := backup.Create(context.Background(), sourceStore(), "event-2025")
+	require.NoError(t, err)
+
+	target := sourceStore()
+	_, err = backup.Restore(context.Background(), target, archive, nil)
+	assert.ErrorIs(t, err, backup.ErrNotEmpty)
+	assert.Empty(t, target.written, "nothing is written when the target is not empty")
+}
+
+func TestRestore_ResumeAfterFailure(t *testing.T) {
+	source := sourceStore()
+	source.speakers = append(source.speakers, models.Speaker{ID: "sp2", Name: "Grace Hopper"})
+	archive, err := backup.Create(context.Background(), source, "event-2025")
+	require.NoError(t, err)
+
+	target := &memoryStore{failWrite: "speakers"}
+	result, err := backup.Restore(context.Background(), target, archive, nil)
+	require.Error(t, err)
+	assert.Equal(t, backup.RestoreResult{"attendees": 1, "speakers": 1}, result, "the failure reports what was written")
+
+	// A plain retry is refused, but resuming writes everything again.
+	_, err = backup.Restore(context.Background(), target, archive, nil)
+	assert.ErrorIs(t, err, backup.ErrNotEmpty)
+	target.failWrite = ""
+	result, err = backup.Resume(context.Background(), target, archive, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result["speakers"])
+	assert.Equal(t, 1, result["sessions"])
+}
+
+func TestResume_RefusesDocumentsNotInArchive(t *testing.T) {
+	archive, err := backup.Create(context.Background(), sourceStore(), "event-2025")
+	require.NoError(t, err)
+
+	target := &memoryStore{speakers: []models.Speaker{{ID: "someone-else"}}}
+	_, err = backup.Resume(context.Background(), target, archive, nil)
+	assert.ErrorIs(t, err, backup.ErrNotEmpty)
+	assert.Empty(t, target.written)
+}
+
+func TestRestore_UnknownCollection(t *testing.T) {
+	_, err := backup.Restore(context.Background(), &memoryStore{}, &backup.Archive{Version: 1}, []string{"tickets"})
+	assert.ErrorIs(t, err, backup.ErrUnknownCollection)
+}
+
+func TestRead_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Not JSON", input: "nope"},
+		{name: "Missing version", input: `{"speakers":[]}`},
+		{name: "Future version", input: `{"version":99}`},
+		{name: "Document without id", input: `{"version":1,"speakers":[{"name":"Ada"}]}`},
+		{name: "Unknown field", input: `{"version":2,"tickets":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := backup.Read(strings.NewReader(tt.input))
+			assert.Error(t, err)
+		})
+	}
+}
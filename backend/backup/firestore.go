@@ -0,0 +1,174 @@
+package backup
+
+import (
+	"context"
+	"event-registration-backend/migrations"
+	"event-registration-backend/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// batchSize is Firestore's limit on writes per transaction.
+const batchSize = 500
+
+// FirestoreStore reads and writes the collections of one event, rooted at
+// clients/{ClientID}.
+type FirestoreStore struct {
+	Client *firestore.Client
+	Root   *firestore.DocumentRef
+}
+
+func NewFirestoreStore(client *firestore.Client, clientID string) *FirestoreStore {
+	return &FirestoreStore{
+		Client: client,
+		Root:   client.Collection("clients").Doc(clientID),
+	}
+}
+
+func (s *FirestoreStore) ListAttendees(ctx context.Context) ([]models.Attendee, error) {
+	var attendees []models.Attendee
+	err := s.each(ctx, CollectionAttendees, func(doc *firestore.DocumentSnapshot) {
+		var a models.Attendee
+		if doc.DataTo(&a) == nil {
+			a.ID = doc.Ref.ID
+			attendees = append(attendees, a)
+		}
+	})
+	return attendees, err
+}
+
+func (s *FirestoreStore) ListSpeakers(ctx context.Context) ([]models.Speaker, error) {
+	var speakers []models.Speaker
+	err := s.each(ctx, CollectionSpeakers, func(doc *firestore.DocumentSnapshot) {
+		var sp models.Speaker
+		if doc.DataTo(&sp) == nil {
+			sp.ID = doc.Ref.ID
+			speakers = append(speakers, sp)
+		}
+	})
+	return speakers, err
+}
+
+func (s *FirestoreStore) ListSessions(ctx context.Context) ([]models.Session, error) {
+	var sessions []models.Session
+	err := s.each(ctx, CollectionSessions, func(doc *firestore.DocumentSnapshot) {
+		var se models.Session
+		if doc.DataTo(&se) == nil {
+			se.ID = doc.Ref.ID
+			sessions = append(sessions, se)
+		}
+	})
+	return sessions, err
+}
+
+func (s *FirestoreStore) GetSettings(ctx context.Context) (Settings, error) {
+	var settings Settings
+	doc, err := s.Root.Collection(CollectionSettings).Doc(models.EmailDomainPolicyID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	var policy models.EmailDomainPolicy
+	if err := doc.DataTo(&policy); err != nil {
+		return settings, err
+	}
+	settings.EmailDomains = &policy
+	return settings, nil
+}
+
+func (s *FirestoreStore) ListMigrations(ctx context.Context) ([]migrations.Record, error) {
+	var records []migrations.Record
+	err := s.each(ctx, CollectionMigrations, func(doc *firestore.DocumentSnapshot) {
+		var rec migrations.Record
+		if doc.DataTo(&rec) == nil {
+			rec.ID = doc.Ref.ID
+			records = append(records, rec)
+		}
+	})
+	return records, err
+}
+
+func (s *FirestoreStore) each(ctx context.Context, collection string, fn func(*firestore.DocumentSnapshot)) error {
+	iter := s.Root.Collection(collection).Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fn(doc)
+	}
+}
+
+func (s *FirestoreStore) IsEmpty(ctx context.Context, collection string) (bool, error) {
+	iter := s.Root.Collection(collection).Limit(1).Documents(ctx)
+	defer iter.Stop()
+	_, err := iter.Next()
+	if err == iterator.Done {
+		return true, nil
+	}
+	return false, err
+}
+
+// ListIDs lists document references only, without reading their data.
+func (s *FirestoreStore) ListIDs(ctx context.Context, collection string) ([]string, error) {
+	refs, err := s.Root.Collection(collection).DocumentRefs(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID
+	}
+	return ids, nil
+}
+
+// Write commits docs in transactions of up to batchSize writes.
+func (s *FirestoreStore) Write(ctx context.Context, collection string, docs []Document) (int, error) {
+	ref := s.Root.Collection(collection)
+	written := 0
+	for start := 0; start < len(docs); start += batchSize {
+		batch := docs[start:min(start+batchSize, len(docs))]
+		err := s.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			for _, doc := range batch {
+				if err := tx.Set(ref.Doc(doc.ID), doc.Data); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return written, err
+		}
+		written += len(batch)
+	}
+	return written, nil
+}
+
+// Delete removes docs in transactions of up to batchSize deletes.
+func (s *FirestoreStore) Delete(ctx context.Context, collection string, ids []string) error {
+	ref := s.Root.Collection(collection)
+	for start := 0; start < len(ids); start += batchSize {
+		batch := ids[start:min(start+batchSize, len(ids))]
+		err := s.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			for _, id := range batch {
+				if err := tx.Delete(ref.Doc(id)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Package challenge checks that a registration was made by a person rather
+// than a script. A Verifier describes a challenge the registration form must
+// solve and verifies the solution sent with the registration.
+package challenge
+
+import (
+	"context"
+	"errors"
+	"event-registration-backend/models"
+	"net/http"
+)
+
+// Challenge types.
+const (
+	TypeNone        = "none"
+	TypeProofOfWork = "proof-of-work"
+)
+
+// ErrFailed means the registration did not solve the challenge.
+var ErrFailed = errors.New("challenge: not solved")
+
+// Challenge tells clients what to solve before registering.
+type Challenge struct {
+	Type string `json:"type"`
+	// Difficulty is the number of leading zero bits a proof-of-work hash
+	// must have.
+	Difficulty int `json:"difficulty,omitempty"`
+}
+
+// Verifier is implemented by each kind of challenge, such as a proof of work
+// or a CAPTCHA service.
+type Verifier interface {
+	// Challenge describes the challenge to clients.
+	Challenge() Challenge
+	// Verify checks req.Challenge, the client's solution. It returns
+	// ErrFailed when the solution is missing or wrong, and any other error
+	// when the solution could not be checked.
+	Verify(ctx context.Context, r *http.Request, req models.RegisterRequest) error
+}
@@ -0,0 +1,51 @@
+package challenge
+
+import (
+	"context"
+	"crypto/sha256"
+	"event-registration-backend/models"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// solve finds a proof-of-work nonce the way the registration form does.
+func solve(email string, difficulty int) string {
+	for nonce := 0; ; nonce++ {
+		s := strconv.Itoa(nonce)
+		sum := sha256.Sum256([]byte(powPrefix + email + ":" + s))
+		if leadingZeroBits(sum[:]) >= difficulty {
+			return s
+		}
+	}
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	assert.Equal(t, 0, leadingZeroBits([]byte{0x80}))
+	assert.Equal(t, 7, leadingZeroBits([]byte{0x01, 0xff}))
+	assert.Equal(t, 12, leadingZeroBits([]byte{0x00, 0x08}))
+	assert.Equal(t, 16, leadingZeroBits([]byte{0x00, 0x00}))
+}
+
+func TestProofOfWork(t *testing.T) {
+	pow := ProofOfWork{Difficulty: 12}
+	assert.Equal(t, Challenge{Type: TypeProofOfWork, Difficulty: 12}, pow.Challenge())
+
+	req := models.RegisterRequest{Email: "Ada@example.com", Challenge: solve("ada@example.com", 12)}
+	assert.NoError(t, pow.Verify(context.Background(), nil, req))
+
+	// The solution is bound to the email
+	other := req
+	other.Email = "grace@example.com"
+	assert.ErrorIs(t, pow.Verify(context.Background(), nil, other), ErrFailed)
+
+	missing := req
+	missing.Challenge = ""
+	assert.ErrorIs(t, pow.Verify(context.Background(), nil, missing), ErrFailed)
+
+	// Expected work doubles per bit, so a 12-bit solution rarely passes 24
+	assert.ErrorIs(t, ProofOfWork{Difficulty: 24}.Verify(context.Background(), nil, models.RegisterRequest{
+		Email: "ada@example.com", Challenge: solve("ada@example.com", 0),
+	}), ErrFailed)
+}
@@ -0,0 +1,53 @@
+package challenge
+
+import (
+	"context"
+	"crypto/sha256"
+	"event-registration-backend/models"
+	"math/bits"
+	"net/http"
+	"strings"
+)
+
+// powPrefix keeps hashes computed for this service from being useful
+// anywhere else.
+const powPrefix = "event-registration:"
+
+// ProofOfWork requires clients to find a nonce such that
+//
+//	SHA-256("event-registration:" + lower(email) + ":" + nonce)
+//
+// starts with Difficulty zero bits, and to send the nonce as the solution.
+// Each additional bit doubles the expected work; 16 bits takes a browser
+// about a second. The hash covers the email, so a solution only works for
+// that address, and replaying it while the registration is pending sends
+// at most one link per resend interval.
+type ProofOfWork struct {
+	Difficulty int
+}
+
+func (p ProofOfWork) Challenge() Challenge {
+	return Challenge{Type: TypeProofOfWork, Difficulty: p.Difficulty}
+}
+
+func (p ProofOfWork) Verify(ctx context.Context, r *http.Request, req models.RegisterRequest) error {
+	if req.Challenge == "" || len(req.Challenge) > 64 {
+		return ErrFailed
+	}
+	sum := sha256.Sum256([]byte(powPrefix + strings.ToLower(req.Email) + ":" + req.Challenge))
+	if leadingZeroBits(sum[:]) < p.Difficulty {
+		return ErrFailed
+	}
+	return nil
+}
+
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if c != 0 {
+			return n + bits.LeadingZeros8(c)
+		}
+		n += 8
+	}
+	return n
+}
@@ -0,0 +1,73 @@
+// Package checkin records attendees arriving at the event. The admin API
+// and eventctl both check in through it so they apply the same rules.
+package checkin
+
+import (
+	"context"
+	"errors"
+	"event-registration-backend/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound is returned when no attendee has the given ID or email.
+	ErrNotFound = errors.New("attendee not found")
+	// ErrNotRegistered is returned for attendees who have not confirmed
+	// their email address and so hold no seat.
+	ErrNotRegistered = errors.New("attendee has not confirmed their registration")
+)
+
+// CheckIn marks the attendee with the given ID, or if id is empty the given
+// email, as checked in and returns them. Checking in an attendee twice
+// keeps the first time.
+func CheckIn(ctx context.Context, client *firestore.Client, attendees *firestore.CollectionRef, id, email string) (models.Attendee, error) {
+	var ref *firestore.DocumentRef
+	if id != "" {
+		ref = attendees.Doc(id)
+	} else {
+		iter := attendees.Where("email", "==", models.NormalizeEmail(email)).Limit(1).Documents(ctx)
+		doc, err := iter.Next()
+		iter.Stop()
+		if err == iterator.Done {
+			return models.Attendee{}, ErrNotFound
+		}
+		if err != nil {
+			return models.Attendee{}, err
+		}
+		ref = doc.Ref
+	}
+
+	var attendee models.Attendee
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		attendee = models.Attendee{}
+		if err := doc.DataTo(&attendee); err != nil {
+			return err
+		}
+		attendee.ID = ref.ID
+		if attendee.Status != models.StatusRegistered {
+			return ErrNotRegistered
+		}
+		if attendee.CheckedInAt != nil {
+			return nil
+		}
+		now := time.Now()
+		attendee.CheckedInAt = &now
+		return tx.Update(ref, []firestore.Update{{Path: "checkedInAt", Value: now}})
+	})
+	if err != nil {
+		return models.Attendee{}, err
+	}
+	return attendee, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"event-registration-backend/auth"
+	"event-registration-backend/firestore"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func runCreateAdmin(ctx context.Context, args []string) error {
+	username, err := parseCreateAdminArgs(args)
+	if err != nil {
+		return err
+	}
+
+	// The password is read from stdin rather than a flag so it does not end
+	// up in shell history or the process list.
+	fmt.Fprintf(os.Stderr, "Password for %s (at least %d characters): ", username, auth.MinPasswordLength)
+	password, err := readPassword(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	if _, err := connect(); err != nil {
+		return err
+	}
+	defer firestore.Client.Close()
+
+	if err := auth.CreateAdmin(ctx, firestore.GetAdminsCollection(), username, password); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Created admin %s\n", username)
+	return nil
+}
+
+// parseCreateAdminArgs reads the create-admin flags and returns the
+// username, checked before the password is asked for.
+func parseCreateAdminArgs(args []string) (string, error) {
+	fs := newFlagSet("create-admin")
+	username := fs.String("username", "", "login name for the new admin (required)")
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if *username == "" {
+		fs.Usage()
+		return "", errors.New("-username is required")
+	}
+	if err := auth.ValidateUsername(*username); err != nil {
+		return "", err
+	}
+	return *username, nil
+}
+
+// readPassword reads one line from r and checks it is a usable password.
+func readPassword(r io.Reader) (string, error) {
+	password, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && password == "" {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if err := auth.ValidatePassword(password); err != nil {
+		return "", err
+	}
+	return password, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCreateAdminArgs(t *testing.T) {
+	username, err := parseCreateAdminArgs([]string{"-username", "ops.lead"})
+	require.NoError(t, err)
+	assert.Equal(t, "ops.lead", username)
+
+	_, err = parseCreateAdminArgs(nil)
+	assert.EqualError(t, err, "-username is required")
+
+	// Rejected before the password is asked for
+	_, err = parseCreateAdminArgs([]string{"-username", "Ops Lead"})
+	assert.Error(t, err)
+}
+
+func TestReadPassword(t *testing.T) {
+	password, err := readPassword(strings.NewReader("correct horse battery\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "correct horse battery", password)
+
+	_, err = readPassword(strings.NewReader("short\n"))
+	assert.Error(t, err)
+
+	_, err = readPassword(strings.NewReader(""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/backup"
+	"event-registration-backend/checkin"
+	"event-registration-backend/export"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// attendeeFilter holds the -designation and -status flags shared by the
+// attendee commands.
+type attendeeFilter struct {
+	designation string
+	status      string
+}
+
+func (f *attendeeFilter) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.designation, "designation", "", "only attendees with this designation")
+	fs.StringVar(&f.status, "status", "", "only attendees with this status")
+}
+
+// load returns the matching attendees, oldest registration first.
+func (f *attendeeFilter) load(ctx context.Context, clientID string) ([]models.Attendee, error) {
+	all, err := backup.NewFirestoreStore(firestore.Client, clientID).ListAttendees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var attendees []models.Attendee
+	for _, a := range all {
+		if f.designation != "" && a.Designation != f.designation {
+			continue
+		}
+		if f.status != "" && a.Status != f.status {
+			continue
+		}
+		attendees = append(attendees, a)
+	}
+	sort.Slice(attendees, func(i, j int) bool {
+		return attendees[i].CreatedAt.Before(attendees[j].CreatedAt)
+	})
+	return attendees, nil
+}
+
+func runListAttendees(ctx context.Context, args []string) error {
+	fs := newFlagSet("list-attendees")
+	var filter attendeeFilter
+	filter.register(fs)
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := connect()
+	if err != nil {
+		return err
+	}
+	defer firestore.Client.Close()
+
+	attendees, err := filter.load(ctx, cfg.ClientID)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(attendees)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tEMAIL\tDESIGNATION\tSTATUS\tREGISTERED\tCHECKED IN")
+	for _, a := range attendees {
+		checkedIn := "-"
+		if a.CheckedInAt != nil {
+			checkedIn = a.CheckedInAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", a.ID, a.FullName, a.Email, a.Designation,
+			a.Status, a.CreatedAt.Local().Format(time.DateTime), checkedIn)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d attendees\n", len(attendees))
+	return nil
+}
+
+func runExportAttendees(ctx context.Context, args []string) error {
+	opts, err := parseExportArgs(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := connect()
+	if err != nil {
+		return err
+	}
+	defer firestore.Client.Close()
+
+	attendees, err := opts.filter.load(ctx, cfg.ClientID)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w, err := export.NewWriter(opts.format, out)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(export.Headers(opts.columns)); err != nil {
+		return err
+	}
+	for _, a := range attendees {
+		if err := w.Write(export.Row(opts.columns, a, opts.loc)); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d attendees\n", len(attendees))
+	return nil
+}
+
+// exportOptions are the export-attendees command's flags, checked before
+// connecting or creating the output file.
+type exportOptions struct {
+	filter  attendeeFilter
+	format  string
+	output  string
+	columns []export.Column
+	loc     *time.Location
+}
+
+func parseExportArgs(args []string) (exportOptions, error) {
+	var opts exportOptions
+	fs := newFlagSet("export-attendees")
+	opts.filter.register(fs)
+	fs.StringVar(&opts.format, "format", export.FormatCSV, "csv or xlsx")
+	fs.StringVar(&opts.output, "o", "", "file to write (default stdout)")
+	columns := fs.String("columns", "", "comma-separated columns (default all)")
+	tz := fs.String("tz", "UTC", "IANA time zone for timestamps")
+	if err := fs.Parse(args); err != nil {
+		return exportOptions{}, err
+	}
+
+	if opts.format != export.FormatCSV && opts.format != export.FormatXLSX {
+		return exportOptions{}, fmt.Errorf("-format must be %s or %s", export.FormatCSV, export.FormatXLSX)
+	}
+	var err error
+	if opts.columns, err = export.ParseColumns(*columns); err != nil {
+		return exportOptions{}, err
+	}
+	if opts.loc, err = time.LoadLocation(*tz); err != nil {
+		return exportOptions{}, err
+	}
+	return opts, nil
+}
+
+// runCheckIn marks a registered attendee as checked in. Servers watch for
+// check-ins, so it reaches the admin dashboard like one made there.
+func runCheckIn(ctx context.Context, args []string) error {
+	target, err := parseCheckInArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if _, err := connect(); err != nil {
+		return err
+	}
+	defer firestore.Client.Close()
+
+	attendee, err := checkin.CheckIn(ctx, firestore.Client, firestore.GetAttendeesCollection(), target.id, target.email)
+	if errors.Is(err, checkin.ErrNotFound) {
+		return fmt.Errorf("no attendee registered with %s", target)
+	}
+	if errors.Is(err, checkin.ErrNotRegistered) {
+		return fmt.Errorf("%s has not confirmed their registration", target)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s <%s> checked in at %s\n", attendee.FullName, attendee.Email, attendee.CheckedInAt.Local().Format(time.DateTime))
+	return nil
+}
+
+// checkInTarget is the attendee named on the checkin command line, by ID or
+// by email.
+type checkInTarget struct {
+	id    string
+	email string
+}
+
+func (t checkInTarget) String() string {
+	if t.id != "" {
+		return "ID " + t.id
+	}
+	return t.email
+}
+
+// parseCheckInArgs reads the checkin flags. Exactly one of -email or -id
+// must be given; the email is normalized as it was when registering.
+func parseCheckInArgs(args []string) (checkInTarget, error) {
+	fs := newFlagSet("checkin")
+	email := fs.String("email", "", "email of the attendee to check in")
+	id := fs.String("id", "", "ID of the attendee to check in")
+	if err := fs.Parse(args); err != nil {
+		return checkInTarget{}, err
+	}
+	if (*email == "") == (*id == "") {
+		fs.Usage()
+		return checkInTarget{}, errors.New("exactly one of -email or -id is required")
+	}
+	return checkInTarget{id: *id, email: models.NormalizeEmail(*email)}, nil
+}
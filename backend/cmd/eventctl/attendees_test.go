@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCheckInArgs(t *testing.T) {
+	target, err := parseCheckInArgs([]string{"-email", " Jane@Example.COM "})
+	require.NoError(t, err)
+	assert.Equal(t, checkInTarget{email: "Jane@example.com"}, target, "emails are matched as registration stored them")
+
+	target, err = parseCheckInArgs([]string{"-id", "a1"})
+	require.NoError(t, err)
+	assert.Equal(t, checkInTarget{id: "a1"}, target)
+	assert.Equal(t, "ID a1", target.String())
+
+	for _, args := range [][]string{nil, {"-id", "a1", "-email", "jane@example.com"}} {
+		_, err := parseCheckInArgs(args)
+		assert.EqualError(t, err, "exactly one of -email or -id is required", "%v", args)
+	}
+}
+
+func TestParseExportArgs(t *testing.T) {
+	opts, err := parseExportArgs([]string{"-format", "xlsx", "-o", "out.xlsx", "-columns", "fullName,email", "-tz", "Asia/Kolkata", "-status", "registered"})
+	require.NoError(t, err)
+	assert.Equal(t, "xlsx", opts.format)
+	assert.Equal(t, "out.xlsx", opts.output)
+	assert.Len(t, opts.columns, 2)
+	assert.Equal(t, "Asia/Kolkata", opts.loc.String())
+	assert.Equal(t, "registered", opts.filter.status)
+
+	for _, args := range [][]string{{"-format", "pdf"}, {"-columns", "password"}, {"-tz", "Mars/Olympus_Mons"}} {
+		_, err := parseExportArgs(args)
+		assert.Error(t, err, "%v", args)
+	}
+}
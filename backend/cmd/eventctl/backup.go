@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"event-registration-backend/backup"
+	"event-registration-backend/firestore"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func runBackup(ctx context.Context, args []string) error {
+	fs := newFlagSet("backup")
+	output := fs.String("o", "", "archive file to write (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := connect()
+	if err != nil {
+		return err
+	}
+	defer firestore.Client.Close()
+
+	archive, err := backup.Create(ctx, backup.NewFirestoreStore(firestore.Client, cfg.ClientID), cfg.ClientID)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := backup.Write(w, archive); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Backed up %d attendees, %d speakers, %d sessions\n",
+		len(archive.Attendees), len(archive.Speakers), len(archive.Sessions))
+	return nil
+}
+
+func runRestore(ctx context.Context, args []string) error {
+	fs := newFlagSet("restore")
+	input := fs.String("i", "", "archive file to read (required)")
+	only := fs.String("collections", "", "comma-separated collections to restore, e.g. speakers,sessions (default all)")
+	resume := fs.Bool("resume", false, "finish a restore of the same archive that failed partway")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		fs.Usage()
+		return errors.New("-i is required")
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	archive, err := backup.Read(f)
+	if err != nil {
+		return err
+	}
+
+	var collections []string
+	if *only != "" {
+		collections = strings.Split(*only, ",")
+	}
+
+	cfg, err := connect()
+	if err != nil {
+		return err
+	}
+	defer firestore.Client.Close()
+
+	restore := backup.Restore
+	if *resume {
+		restore = backup.Resume
+	}
+	result, err := restore(ctx, backup.NewFirestoreStore(firestore.Client, cfg.ClientID), archive, collections)
+	for collection, n := range result {
+		fmt.Fprintf(os.Stderr, "Restored %d %s into event %s\n", n, collection, cfg.ClientID)
+	}
+	if err != nil && len(result) > 0 {
+		return fmt.Errorf("%w; run the same restore again with -resume to finish it", err)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+// Command eventctl performs operational tasks against the event's storage
+// without going through the HTTP API.
+//
+// Usage:
+//
+//	eventctl <command> [flags]
+//
+// It reads the same environment (and .env file) as the server, so
+// FIRESTORE_CREDENTIALS_PATH and CLIENT_ID select which event it works on.
+package main
+
+import (
+	"context"
+	"event-registration-backend/config"
+	"event-registration-backend/firestore"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/joho/godotenv"
+)
+
+// command is one eventctl subcommand.
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"backup":           {"write the event to a JSON archive", runBackup},
+	"restore":          {"load a JSON archive into an empty event", runRestore},
+	"seed":             {"add or update speakers and sessions from a YAML file", runSeed},
+	"list-attendees":   {"print attendees of every status, or those matching -status", runListAttendees},
+	"export-attendees": {"write attendees to a CSV or XLSX file", runExportAttendees},
+	"checkin":          {"mark an attendee as checked in", runCheckIn},
+	"create-admin":     {"add a named dashboard admin", runCreateAdmin},
+	"migrate":          {"apply pending schema migrations", runMigrate},
+}
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "eventctl: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(context.Background(), os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "eventctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: eventctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-17s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'eventctl <command> -h' for command flags.")
+}
+
+// newFlagSet returns a flag set for a subcommand that reports errors
+// instead of exiting.
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("eventctl "+name, flag.ContinueOnError)
+}
+
+// connect loads configuration and opens the Firestore client.
+func connect() (*config.Config, error) {
+	cfg := config.LoadConfig()
+	if err := firestore.InitializeFirestore(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
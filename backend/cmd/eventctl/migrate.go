@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"event-registration-backend/firestore"
+	"event-registration-backend/migrations"
+	"fmt"
+	"os"
+)
+
+func runMigrate(ctx context.Context, args []string) error {
+	fs := newFlagSet("migrate")
+	status := fs.Bool("status", false, "list applied and pending migrations without running any")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := connect()
+	if err != nil {
+		return err
+	}
+	defer firestore.Client.Close()
+
+	ev := migrations.NewEvent(firestore.Client, cfg.ClientID)
+	ledger := migrations.NewFirestoreLedger(ev)
+
+	if *status {
+		applied, err := ledger.Applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations.All() {
+			if rec, ok := applied[m.ID]; ok {
+				fmt.Printf("applied  %s  %s  (%s)\n", m.ID, m.Description, rec.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("pending  %s  %s\n", m.ID, m.Description)
+			}
+		}
+		return nil
+	}
+
+	ran, err := migrations.Run(ctx, ev, ledger, migrations.All())
+	for _, id := range ran {
+		fmt.Fprintf(os.Stderr, "Applied %s to event %s\n", id, cfg.ClientID)
+	}
+	if err == nil && len(ran) == 0 {
+		fmt.Fprintf(os.Stderr, "Event %s is up to date\n", cfg.ClientID)
+	}
+	return err
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"event-registration-backend/backup"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// seedFile is the YAML layout accepted by the seed command:
+//
+//	speakers:
+//	  - id: ada
+//	    name: Ada Lovelace
+//	    bio: First programmer
+//	    photoURL: https://example.com/ada.jpg
+//	sessions:
+//	  - id: keynote
+//	    title: Opening Keynote
+//	    description: Welcome and agenda
+//	    time: "09:30 AM"
+//	    speakerId: ada
+//
+// IDs are required so seeding the same file twice updates rather than
+// duplicates.
+type seedFile struct {
+	Speakers []struct {
+		ID       string `yaml:"id"`
+		Name     string `yaml:"name"`
+		Bio      string `yaml:"bio"`
+		PhotoURL string `yaml:"photoURL"`
+	} `yaml:"speakers"`
+	Sessions []struct {
+		ID          string `yaml:"id"`
+		Title       string `yaml:"title"`
+		Description string `yaml:"description"`
+		Time        string `yaml:"time"`
+		SpeakerID   string `yaml:"speakerId"`
+	} `yaml:"sessions"`
+}
+
+func runSeed(ctx context.Context, args []string) error {
+	opts, err := parseSeedArgs(args)
+	if err != nil {
+		return err
+	}
+	// Everything that can be checked without storage is checked before
+	// connecting
+	plan, err := readSeedFile(opts.file)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := connect()
+	if err != nil {
+		return err
+	}
+	defer firestore.Client.Close()
+	store := backup.NewFirestoreStore(firestore.Client, cfg.ClientID)
+
+	existing, err := store.ListSpeakers(ctx)
+	if err != nil {
+		return err
+	}
+	if err := plan.checkSpeakers(existing); err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		fmt.Fprintf(os.Stderr, "%s is valid: %d speakers, %d sessions\n", opts.file, len(plan.speakers), len(plan.sessions))
+		return nil
+	}
+
+	var speakers, sessions []backup.Document
+	for _, speaker := range plan.speakers {
+		speakers = append(speakers, backup.Document{ID: speaker.ID, Data: speaker})
+	}
+	for _, session := range plan.sessions {
+		sessions = append(sessions, backup.Document{ID: session.ID, Data: session})
+	}
+	if _, err := store.Write(ctx, backup.CollectionSpeakers, speakers); err != nil {
+		return err
+	}
+	if _, err := store.Write(ctx, backup.CollectionSessions, sessions); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Seeded %d speakers and %d sessions into event %s\n", len(speakers), len(sessions), cfg.ClientID)
+	return nil
+}
+
+// seedOptions are the seed command's flags.
+type seedOptions struct {
+	file   string
+	dryRun bool
+}
+
+func parseSeedArgs(args []string) (seedOptions, error) {
+	var opts seedOptions
+	fs := newFlagSet("seed")
+	fs.StringVar(&opts.file, "f", "", "YAML file with speakers and sessions (required)")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "validate the file without writing")
+	if err := fs.Parse(args); err != nil {
+		return seedOptions{}, err
+	}
+	if opts.file == "" {
+		fs.Usage()
+		return seedOptions{}, errors.New("-f is required")
+	}
+	return opts, nil
+}
+
+// seedPlan is a validated seed file, ready to write.
+type seedPlan struct {
+	speakers []models.Speaker
+	sessions []models.Session
+	// rows holds each session's index in the file, for error messages
+	rows []int
+}
+
+// readSeedFile parses and validates a seed file. Sessions whose speaker is
+// not in the file are left for checkSpeakers.
+func readSeedFile(path string) (*seedPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var seed seedFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&seed); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	plan := &seedPlan{}
+	var problems []error
+	for i, s := range seed.Speakers {
+		speaker := models.Speaker{ID: s.ID, Name: s.Name, Bio: s.Bio, PhotoURL: s.PhotoURL, SchemaVersion: models.SchemaVersion}
+		speaker.Normalize()
+		if err := speaker.Validate(); err != nil {
+			problems = append(problems, fmt.Errorf("speakers[%d]: %w", i, err))
+			continue
+		}
+		if speaker.ID == "" {
+			problems = append(problems, fmt.Errorf("speakers[%d]: id is required", i))
+			continue
+		}
+		plan.speakers = append(plan.speakers, speaker)
+	}
+	for i, s := range seed.Sessions {
+		session := models.Session{ID: s.ID, Title: s.Title, Description: s.Description, Time: s.Time, SpeakerID: s.SpeakerID, SchemaVersion: models.SchemaVersion}
+		session.Normalize()
+		if err := session.Validate(); err != nil {
+			problems = append(problems, fmt.Errorf("sessions[%d]: %w", i, err))
+			continue
+		}
+		if session.ID == "" {
+			problems = append(problems, fmt.Errorf("sessions[%d]: id is required", i))
+			continue
+		}
+		plan.sessions = append(plan.sessions, session)
+		plan.rows = append(plan.rows, i)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return plan, nil
+}
+
+// checkSpeakers reports sessions whose speaker is neither in the file nor
+// among the stored speakers.
+func (p *seedPlan) checkSpeakers(stored []models.Speaker) error {
+	known := make(map[string]bool, len(stored)+len(p.speakers))
+	for _, s := range stored {
+		known[s.ID] = true
+	}
+	for _, s := range p.speakers {
+		known[s.ID] = true
+	}
+
+	var problems []error
+	for i, session := range p.sessions {
+		if session.SpeakerID != "" && !known[session.SpeakerID] {
+			problems = append(problems, fmt.Errorf("sessions[%d]: unknown speakerId %q", p.rows[i], session.SpeakerID))
+		}
+	}
+	return errors.Join(problems...)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"event-registration-backend/models"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSeedFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestParseSeedArgs(t *testing.T) {
+	opts, err := parseSeedArgs([]string{"-f", "agenda.yaml", "-dry-run"})
+	require.NoError(t, err)
+	assert.Equal(t, seedOptions{file: "agenda.yaml", dryRun: true}, opts)
+
+	_, err = parseSeedArgs(nil)
+	assert.EqualError(t, err, "-f is required")
+
+	_, err = parseSeedArgs([]string{"-force"})
+	assert.Error(t, err)
+}
+
+func TestReadSeedFile(t *testing.T) {
+	path := writeSeedFile(t, `
+speakers:
+  - id: ada
+    name: " Ada Lovelace "
+sessions:
+  - id: keynote
+    title: Opening Keynote
+    speakerId: ada
+  - id: panel
+    title: Panel
+    speakerId: grace
+`)
+
+	plan, err := readSeedFile(path)
+	require.NoError(t, err)
+	require.Len(t, plan.speakers, 1)
+	assert.Equal(t, "Ada Lovelace", plan.speakers[0].Name)
+	assert.Equal(t, models.SchemaVersion, plan.speakers[0].SchemaVersion)
+	assert.Len(t, plan.sessions, 2)
+
+	// Speakers outside the file are only known once storage is read
+	assert.EqualError(t, plan.checkSpeakers(nil), `sessions[1]: unknown speakerId "grace"`)
+	assert.NoError(t, plan.checkSpeakers([]models.Speaker{{ID: "grace"}}))
+}
+
+func TestReadSeedFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "Unknown field", content: "speakers:\n  - id: ada\n    nickname: Ada\n", want: "nickname"},
+		{name: "Missing id", content: "speakers:\n  - name: Ada Lovelace\n", want: "speakers[0]: id is required"},
+		{name: "Invalid session", content: "sessions:\n  - id: keynote\n", want: "sessions[0]:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readSeedFile(writeSeedFile(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	_, err := readSeedFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -1,7 +1,11 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -9,6 +13,63 @@ type Config struct {
 	AdminPassword            string
 	FirestoreCredentialsPath string
 	ClientID                 string
+	MigrateOnStartup         bool
+	// RegisterRateLimit is how many registrations a minute each client IP
+	// may make after a burst of RegisterRateBurst. Zero disables the limit.
+	RegisterRateLimit int
+	RegisterRateBurst int
+	// TrustedProxies lists the IPs and CIDR ranges of reverse proxies whose
+	// X-Forwarded-For header identifies the client.
+	TrustedProxies []string
+	// RegistrationPoWDifficulty is the number of leading zero bits the
+	// registration proof of work needs. Zero disables the challenge.
+	RegistrationPoWDifficulty int
+	// PublicURL is where people reach the site; links in emails point here.
+	PublicURL string
+	// SMTPHost is the mail server for confirmation emails. When it is
+	// empty, emails are written to the log instead.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	// VerificationHold is how long a seat is held for a registration
+	// waiting for its email to be confirmed.
+	VerificationHold time.Duration
+	// EventCapacity caps confirmed registrations plus held seats. Zero
+	// means unlimited.
+	EventCapacity int
+	// CORS settings for browsers calling the API from other origins. With
+	// no allowed origins only same-origin pages may use the API.
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSAllowCredentials bool
+	CORSMaxAge           time.Duration
+	// ContentSecurityPolicy replaces the default policy sent with every
+	// response when set.
+	ContentSecurityPolicy string
+	// HSTSMaxAge is how long browsers should only reach the site over
+	// HTTPS. Zero disables Strict-Transport-Security.
+	HSTSMaxAge time.Duration
+	// HTTP server timeouts. WriteTimeout bounds ordinary responses; live
+	// feeds manage their own deadlines.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	// after SIGTERM before connections are closed.
+	ShutdownTimeout time.Duration
+	// StorageTimeout bounds the Firestore calls of one request, and
+	// BulkStorageTimeout those of exports, imports, backups and other
+	// whole-collection work.
+	StorageTimeout     time.Duration
+	BulkStorageTimeout time.Duration
+	// StaticDir is a directory holding a frontend build to serve instead of
+	// the one embedded with -tags embedfrontend. It is only used when it
+	// contains index.html.
+	StaticDir string
 }
 
 func LoadConfig() *Config {
@@ -25,13 +86,132 @@ func LoadConfig() *Config {
 	credentialsPath := os.Getenv("FIRESTORE_CREDENTIALS_PATH")
 	// If empty, will use Application Default Credentials (ADC)
 
-	// Client ID from service account JSON
-	clientID := "114617498403471847641"
+	// Client ID from service account JSON. Override with CLIENT_ID to work
+	// on a different event, e.g. when restoring a backup.
+	clientID := os.Getenv("CLIENT_ID")
+	if clientID == "" {
+		clientID = "114617498403471847641"
+	}
+
+	// Pending schema migrations run at startup unless MIGRATE_ON_STARTUP=false,
+	// in which case run them with "eventctl migrate" before deploying.
+	migrateOnStartup := os.Getenv("MIGRATE_ON_STARTUP") != "false"
+
+	// Public registration is throttled per client IP
+	registerRateLimit := intEnv("REGISTER_RATE_LIMIT", 10)
+	registerRateBurst := intEnv("REGISTER_RATE_BURST", 5)
+
+	trustedProxies := listEnv("TRUSTED_PROXIES", nil)
+
+	registrationPoWDifficulty := intEnv("REGISTRATION_POW_DIFFICULTY", 0)
+
+	publicURL := strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + port
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort == "" {
+		smtpPort = "587"
+	}
+
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "noreply@localhost"
+	}
+
+	verificationHold := durationEnv("VERIFICATION_HOLD", 24*time.Hour)
+	eventCapacity := intEnv("EVENT_CAPACITY", 0)
+
+	// Cross-origin access is off unless origins are listed
+	corsAllowedOrigins := listEnv("CORS_ALLOWED_ORIGINS", nil)
+	corsAllowedMethods := listEnv("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE"})
+	corsAllowedHeaders := listEnv("CORS_ALLOWED_HEADERS", []string{"Content-Type", "Authorization"})
+	corsAllowCredentials := os.Getenv("CORS_ALLOW_CREDENTIALS") == "true"
+	corsMaxAge := durationEnv("CORS_MAX_AGE", 10*time.Minute)
+	// HSTS_MAX_AGE=0 turns HSTS off, for sites not yet served over HTTPS
+	hstsMaxAge := time.Duration(0)
+	if os.Getenv("HSTS_MAX_AGE") != "0" {
+		hstsMaxAge = durationEnv("HSTS_MAX_AGE", 180*24*time.Hour)
+	}
 
 	return &Config{
-		Port:                     port,
-		AdminPassword:            adminPassword,
-		FirestoreCredentialsPath: credentialsPath,
-		ClientID:                 clientID,
+		Port:                      port,
+		AdminPassword:             adminPassword,
+		FirestoreCredentialsPath:  credentialsPath,
+		ClientID:                  clientID,
+		MigrateOnStartup:          migrateOnStartup,
+		RegisterRateLimit:         registerRateLimit,
+		RegisterRateBurst:         registerRateBurst,
+		TrustedProxies:            trustedProxies,
+		RegistrationPoWDifficulty: registrationPoWDifficulty,
+		PublicURL:                 publicURL,
+		SMTPHost:                  os.Getenv("SMTP_HOST"),
+		SMTPPort:                  smtpPort,
+		SMTPUsername:              os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:              os.Getenv("SMTP_PASSWORD"),
+		MailFrom:                  mailFrom,
+		VerificationHold:          verificationHold,
+		EventCapacity:             eventCapacity,
+		CORSAllowedOrigins:        corsAllowedOrigins,
+		CORSAllowedMethods:        corsAllowedMethods,
+		CORSAllowedHeaders:        corsAllowedHeaders,
+		CORSAllowCredentials:      corsAllowCredentials,
+		CORSMaxAge:                corsMaxAge,
+		ContentSecurityPolicy:     os.Getenv("CONTENT_SECURITY_POLICY"),
+		HSTSMaxAge:                hstsMaxAge,
+		ReadHeaderTimeout:         durationEnv("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:               durationEnv("HTTP_READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:              durationEnv("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		IdleTimeout:               durationEnv("HTTP_IDLE_TIMEOUT", 2*time.Minute),
+		ShutdownTimeout:           durationEnv("SHUTDOWN_TIMEOUT", 20*time.Second),
+		StorageTimeout:            durationEnv("STORAGE_TIMEOUT", 10*time.Second),
+		BulkStorageTimeout:        durationEnv("BULK_STORAGE_TIMEOUT", 2*time.Minute),
+		StaticDir:                 os.Getenv("STATIC_DIR"),
+	}
+}
+
+// listEnv reads a comma-separated list from the environment, falling back
+// to def when the variable is unset or lists nothing.
+func listEnv(name string, def []string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	if len(list) == 0 {
+		return def
+	}
+	return list
+}
+
+// intEnv reads a non-negative integer from the environment, falling back
+// to def when the variable is unset or invalid.
+func intEnv(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Printf("Ignoring invalid %s=%q, using %d", name, v, def)
+		return def
+	}
+	return n
+}
+
+// durationEnv reads a positive duration such as "30m" or "48h" from the
+// environment, falling back to def when the variable is unset or invalid.
+func durationEnv(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("Ignoring invalid %s=%q, using %s", name, v, def)
+		return def
 	}
+	return d
 }
@@ -4,6 +4,7 @@ import (
 	"event-registration-backend/config"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -104,3 +105,114 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_ClientID(t *testing.T) {
+	t.Setenv("CLIENT_ID", "")
+	defaultID := config.LoadConfig().ClientID
+	assert.NotEmpty(t, defaultID)
+
+	t.Setenv("CLIENT_ID", "event-2026")
+	assert.Equal(t, "event-2026", config.LoadConfig().ClientID)
+}
+
+func TestLoadConfig_MigrateOnStartup(t *testing.T) {
+	t.Setenv("MIGRATE_ON_STARTUP", "")
+	assert.True(t, config.LoadConfig().MigrateOnStartup)
+
+	t.Setenv("MIGRATE_ON_STARTUP", "false")
+	assert.False(t, config.LoadConfig().MigrateOnStartup)
+}
+
+func TestLoadConfig_Registration(t *testing.T) {
+	t.Setenv("REGISTER_RATE_LIMIT", "")
+	t.Setenv("REGISTER_RATE_BURST", "")
+	t.Setenv("TRUSTED_PROXIES", "")
+	t.Setenv("REGISTRATION_POW_DIFFICULTY", "")
+	cfg := config.LoadConfig()
+	assert.Equal(t, 10, cfg.RegisterRateLimit)
+	assert.Equal(t, 5, cfg.RegisterRateBurst)
+	assert.Empty(t, cfg.TrustedProxies)
+	assert.Zero(t, cfg.RegistrationPoWDifficulty)
+
+	t.Setenv("REGISTER_RATE_LIMIT", "0")
+	t.Setenv("REGISTER_RATE_BURST", "lots")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 127.0.0.1,")
+	t.Setenv("REGISTRATION_POW_DIFFICULTY", "16")
+	cfg = config.LoadConfig()
+	assert.Equal(t, 0, cfg.RegisterRateLimit)
+	assert.Equal(t, 5, cfg.RegisterRateBurst)
+	assert.Equal(t, []string{"10.0.0.0/8", "127.0.0.1"}, cfg.TrustedProxies)
+	assert.Equal(t, 16, cfg.RegistrationPoWDifficulty)
+}
+
+func TestLoadConfig_Verification(t *testing.T) {
+	t.Setenv("PORT", "")
+	t.Setenv("PUBLIC_URL", "")
+	t.Setenv("SMTP_PORT", "")
+	t.Setenv("VERIFICATION_HOLD", "")
+	t.Setenv("EVENT_CAPACITY", "")
+	cfg := config.LoadConfig()
+	assert.Equal(t, "http://localhost:8080", cfg.PublicURL)
+	assert.Equal(t, "587", cfg.SMTPPort)
+	assert.Equal(t, 24*time.Hour, cfg.VerificationHold)
+	assert.Zero(t, cfg.EventCapacity)
+
+	t.Setenv("PUBLIC_URL", "https://events.example.com/")
+	t.Setenv("VERIFICATION_HOLD", "30m")
+	t.Setenv("EVENT_CAPACITY", "150")
+	cfg = config.LoadConfig()
+	assert.Equal(t, "https://events.example.com", cfg.PublicURL)
+	assert.Equal(t, 30*time.Minute, cfg.VerificationHold)
+	assert.Equal(t, 150, cfg.EventCapacity)
+
+	t.Setenv("VERIFICATION_HOLD", "-1h")
+	assert.Equal(t, 24*time.Hour, config.LoadConfig().VerificationHold)
+}
+
+func TestLoadConfig_SecurityHeaders(t *testing.T) {
+	t.Setenv("CONTENT_SECURITY_POLICY", "")
+	t.Setenv("HSTS_MAX_AGE", "")
+	cfg := config.LoadConfig()
+	assert.Empty(t, cfg.ContentSecurityPolicy)
+	assert.Equal(t, 180*24*time.Hour, cfg.HSTSMaxAge)
+
+	t.Setenv("CONTENT_SECURITY_POLICY", "default-src 'self'")
+	t.Setenv("HSTS_MAX_AGE", "8760h")
+	cfg = config.LoadConfig()
+	assert.Equal(t, "default-src 'self'", cfg.ContentSecurityPolicy)
+	assert.Equal(t, 8760*time.Hour, cfg.HSTSMaxAge)
+
+	t.Setenv("HSTS_MAX_AGE", "0")
+	assert.Zero(t, config.LoadConfig().HSTSMaxAge)
+}
+
+func TestLoadConfig_ServerTimeouts(t *testing.T) {
+	for _, name := range []string{"HTTP_READ_HEADER_TIMEOUT", "HTTP_READ_TIMEOUT", "HTTP_WRITE_TIMEOUT", "HTTP_IDLE_TIMEOUT", "SHUTDOWN_TIMEOUT", "STORAGE_TIMEOUT", "BULK_STORAGE_TIMEOUT"} {
+		t.Setenv(name, "")
+	}
+	cfg := config.LoadConfig()
+	assert.Equal(t, 5*time.Second, cfg.ReadHeaderTimeout)
+	assert.Equal(t, 15*time.Second, cfg.ReadTimeout)
+	assert.Equal(t, 60*time.Second, cfg.WriteTimeout)
+	assert.Equal(t, 2*time.Minute, cfg.IdleTimeout)
+	assert.Equal(t, 20*time.Second, cfg.ShutdownTimeout)
+	assert.Equal(t, 10*time.Second, cfg.StorageTimeout)
+	assert.Equal(t, 2*time.Minute, cfg.BulkStorageTimeout)
+
+	t.Setenv("HTTP_WRITE_TIMEOUT", "5m")
+	t.Setenv("SHUTDOWN_TIMEOUT", "45s")
+	t.Setenv("STORAGE_TIMEOUT", "3s")
+	t.Setenv("BULK_STORAGE_TIMEOUT", "10m")
+	cfg = config.LoadConfig()
+	assert.Equal(t, 5*time.Minute, cfg.WriteTimeout)
+	assert.Equal(t, 45*time.Second, cfg.ShutdownTimeout)
+	assert.Equal(t, 3*time.Second, cfg.StorageTimeout)
+	assert.Equal(t, 10*time.Minute, cfg.BulkStorageTimeout)
+}
+
+func TestLoadConfig_StaticDir(t *testing.T) {
+	t.Setenv("STATIC_DIR", "")
+	assert.Empty(t, config.LoadConfig().StaticDir, "only the embedded frontend by default")
+
+	t.Setenv("STATIC_DIR", "/srv/frontend")
+	assert.Equal(t, "/srv/frontend", config.LoadConfig().StaticDir)
+}
@@ -0,0 +1,95 @@
+// Package emaildomain decides whether an email address may register, based
+// on an admin-managed EmailDomainPolicy and a bundled list of disposable
+// email domains.
+package emaildomain
+
+import (
+	_ "embed"
+	"errors"
+	"event-registration-backend/models"
+	"strings"
+)
+
+// Reasons an address is refused.
+var (
+	ErrBlocked    = errors.New("email domain is blocked")
+	ErrDisposable = errors.New("email domain is disposable")
+	ErrNotAllowed = errors.New("email domain is not allowed")
+)
+
+//go:embed disposable.txt
+var disposableList string
+
+var disposable = parseList(disposableList)
+
+func parseList(list string) map[string]bool {
+	domains := make(map[string]bool)
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			domains[strings.ToLower(line)] = true
+		}
+	}
+	return domains
+}
+
+// Domain returns the lower-cased domain of email, or "" if it has none.
+func Domain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSuffix(email[at+1:], "."))
+}
+
+// IsDisposable reports whether domain or one of its parent domains is on
+// the bundled disposable-domain list.
+func IsDisposable(domain string) bool {
+	for d := domain; d != ""; d = parent(d) {
+		if disposable[d] {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns nil if email may register under policy, and otherwise
+// ErrNotAllowed, ErrBlocked or ErrDisposable. When the domain is covered by
+// both lists the most specific entry wins, so blocking bad.example.com
+// takes effect even though example.com is allowed.
+func Check(policy models.EmailDomainPolicy, email string) error {
+	domain := Domain(email)
+	allowed := longestMatch(domain, policy.Allowed)
+	blocked := longestMatch(domain, policy.Blocked)
+	if blocked > allowed {
+		return ErrBlocked
+	}
+	if allowed > 0 {
+		return nil
+	}
+	if policy.AllowedOnly {
+		return ErrNotAllowed
+	}
+	if IsDisposable(domain) {
+		return ErrDisposable
+	}
+	return nil
+}
+
+// longestMatch returns the length of the longest entry of domains that is
+// domain or a parent of it, or 0 if none is.
+func longestMatch(domain string, domains []string) int {
+	longest := 0
+	for _, d := range domains {
+		if (domain == d || strings.HasSuffix(domain, "."+d)) && len(d) > longest {
+			longest = len(d)
+		}
+	}
+	return longest
+}
+
+// parent strips the first label from domain: "a.b.com" becomes "b.com".
+func parent(domain string) string {
+	_, rest, _ := strings.Cut(domain, ".")
+	return rest
+}
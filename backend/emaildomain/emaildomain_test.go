@@ -0,0 +1,62 @@
+package emaildomain
+
+import (
+	"event-registration-backend/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomain(t *testing.T) {
+	assert.Equal(t, "example.com", Domain("Ada@Example.COM"))
+	assert.Equal(t, "example.com", Domain(`"a@b"@example.com.`))
+	assert.Equal(t, "", Domain("no-at-sign"))
+}
+
+func TestIsDisposable(t *testing.T) {
+	assert.True(t, IsDisposable("mailinator.com"))
+	assert.True(t, IsDisposable("inbox.mailinator.com"))
+	assert.False(t, IsDisposable("example.com"))
+	assert.False(t, IsDisposable("notmailinator.com"))
+	assert.False(t, IsDisposable(""))
+}
+
+func TestDisposableListIsWellFormed(t *testing.T) {
+	assert.Greater(t, len(disposable), 50)
+	for d := range disposable {
+		p := models.EmailDomainPolicy{Blocked: []string{d}}
+		assert.NoError(t, p.Validate(), d)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	open := models.EmailDomainPolicy{Blocked: []string{"spam.example"}, Allowed: []string{"yopmail.com"}}
+	private := models.EmailDomainPolicy{Allowed: []string{"acme.com"}, AllowedOnly: true}
+	nested := models.EmailDomainPolicy{Allowed: []string{"example.com", "ok.bad.example.com"}, Blocked: []string{"bad.example.com"}, AllowedOnly: true}
+
+	tests := []struct {
+		name   string
+		policy models.EmailDomainPolicy
+		email  string
+		want   error
+	}{
+		{name: "ordinary domain", email: "ada@example.com"},
+		{name: "disposable", email: "ada@mailinator.com", want: ErrDisposable},
+		{name: "blocked", policy: open, email: "ada@spam.example", want: ErrBlocked},
+		{name: "blocked subdomain", policy: open, email: "ada@eu.spam.example", want: ErrBlocked},
+		{name: "allowed overrides disposable", policy: open, email: "ada@yopmail.com"},
+		{name: "private event member", policy: private, email: "ada@acme.com"},
+		{name: "private event subsidiary", policy: private, email: "ada@labs.acme.com"},
+		{name: "private event outsider", policy: private, email: "ada@example.com", want: ErrNotAllowed},
+		{name: "lookalike domain", policy: private, email: "ada@notacme.com", want: ErrNotAllowed},
+		{name: "allowed parent", policy: nested, email: "ada@example.com"},
+		{name: "blocked inside allowed parent", policy: nested, email: "ada@bad.example.com", want: ErrBlocked},
+		{name: "subdomain of blocked inside allowed parent", policy: nested, email: "ada@eu.bad.example.com", want: ErrBlocked},
+		{name: "allowed inside blocked", policy: nested, email: "ada@ok.bad.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Check(tt.policy, tt.email))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published when the attendee roster or agenda changes.
+const (
+	TypeRegistration = "registration"
+	TypeCancellation = "cancellation"
+	TypeExpiry       = "registration_expired"
+	TypeCheckIn      = "check_in"
+	TypeSpeakerSaved = "speaker_saved"
+	TypeSessionSaved = "session_saved"
+	TypeImport       = "import"
+	TypeCount        = "count"
+)
+
+// Event is a single change notification fanned out to subscribers.
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+	Time time.Time   `json:"time"`
+}
+
+// Hub fans published events out to any number of subscribers. Publishing
+// never blocks: a subscriber whose buffer is full misses the event rather
+// than stalling the publisher.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[chan Event]struct{})}
+}
+
+// Subscribe registers a new subscriber with the given buffer size. The
+// returned function unsubscribes and closes the channel; it is safe to call
+// more than once.
+func (h *Hub) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers e to every current subscriber. Time is filled in if unset.
+func (h *Hub) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribers returns the number of active subscribers.
+func (h *Hub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
@@ -0,0 +1,61 @@
+package events_test
+
+import (
+	"event-registration-backend/events"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_PublishFansOut(t *testing.T) {
+	hub := events.NewHub()
+
+	a, unsubscribeA := hub.Subscribe(1)
+	defer unsubscribeA()
+	b, unsubscribeB := hub.Subscribe(1)
+	defer unsubscribeB()
+
+	hub.Publish(events.Event{Type: events.TypeRegistration, Data: "john@example.com"})
+
+	for _, ch := range []<-chan events.Event{a, b} {
+		e := <-ch
+		assert.Equal(t, events.TypeRegistration, e.Type)
+		assert.Equal(t, "john@example.com", e.Data)
+		assert.False(t, e.Time.IsZero())
+	}
+}
+
+func TestHub_SlowSubscriberDoesNotBlock(t *testing.T) {
+	hub := events.NewHub()
+
+	slow, unsubscribe := hub.Subscribe(1)
+	defer unsubscribe()
+
+	hub.Publish(events.Event{Type: events.TypeCount, Data: 1})
+	hub.Publish(events.Event{Type: events.TypeCount, Data: 2})
+
+	e := <-slow
+	assert.Equal(t, 1, e.Data)
+	select {
+	case e := <-slow:
+		t.Fatalf("expected second event to be dropped, got %v", e)
+	default:
+	}
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	hub := events.NewHub()
+
+	ch, unsubscribe := hub.Subscribe(1)
+	require.Equal(t, 1, hub.Subscribers())
+
+	unsubscribe()
+	unsubscribe()
+	assert.Equal(t, 0, hub.Subscribers())
+
+	_, ok := <-ch
+	assert.False(t, ok, "channel should be closed after unsubscribe")
+
+	hub.Publish(events.Event{Type: events.TypeCount})
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"event-registration-backend/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Column is one exportable attendee field.
+type Column struct {
+	Key    string
+	Header string
+	Value  func(a models.Attendee, loc *time.Location) string
+}
+
+// AttendeeColumns lists every exportable column in default order.
+var AttendeeColumns = []Column{
+	{Key: "id", Header: "ID", Value: func(a models.Attendee, _ *time.Location) string { return a.ID }},
+	{Key: "fullName", Header: "Full Name", Value: func(a models.Attendee, _ *time.Location) string { return a.FullName }},
+	{Key: "email", Header: "Email", Value: func(a models.Attendee, _ *time.Location) string { return a.Email }},
+	{Key: "designation", Header: "Designation", Value: func(a models.Attendee, _ *time.Location) string { return a.Designation }},
+	{Key: "status", Header: "Status", Value: func(a models.Attendee, _ *time.Location) string { return a.Status }},
+	{Key: "createdAt", Header: "Registered At", Value: func(a models.Attendee, loc *time.Location) string {
+		if a.CreatedAt.IsZero() {
+			return ""
+		}
+		return a.CreatedAt.In(loc).Format(time.RFC3339)
+	}},
+	{Key: "checkedInAt", Header: "Checked In At", Value: func(a models.Attendee, loc *time.Location) string {
+		if a.CheckedInAt == nil {
+			return ""
+		}
+		return a.CheckedInAt.In(loc).Format(time.RFC3339)
+	}},
+}
+
+// ParseColumns resolves a comma-separated list of column keys. An empty
+// list selects every column.
+func ParseColumns(list string) ([]Column, error) {
+	if strings.TrimSpace(list) == "" {
+		return AttendeeColumns, nil
+	}
+
+	byKey := make(map[string]Column, len(AttendeeColumns))
+	for _, c := range AttendeeColumns {
+		byKey[c.Key] = c
+	}
+
+	var columns []Column
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(list, ",") {
+		key = strings.TrimSpace(key)
+		c, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", key)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// Headers returns the header row for columns.
+func Headers(columns []Column) []string {
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header
+	}
+	return headers
+}
+
+// Row renders an attendee as one row of columns, with times in loc.
+func Row(columns []Column, a models.Attendee, loc *time.Location) []string {
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c.Value(a, loc)
+	}
+	return row
+}
@@ -0,0 +1,111 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"event-registration-backend/export"
+	"event-registration-backend/models"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var attendee = models.Attendee{
+	ID:          "a1",
+	FullName:    "Zoë Ångström",
+	Email:       "zoe@example.com",
+	Designation: "=HYPERLINK(\"http://evil\")",
+	Status:      models.StatusRegistered,
+	CreatedAt:   time.Date(2025, 3, 1, 18, 30, 0, 0, time.UTC),
+}
+
+func TestParseColumns(t *testing.T) {
+	all, err := export.ParseColumns("")
+	require.NoError(t, err)
+	assert.Len(t, all, len(export.AttendeeColumns))
+
+	columns, err := export.ParseColumns("email, fullName,email")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Email", "Full Name"}, export.Headers(columns))
+
+	_, err = export.ParseColumns("email,password")
+	assert.Error(t, err)
+}
+
+func TestRow_TimeZone(t *testing.T) {
+	columns, err := export.ParseColumns("createdAt")
+	require.NoError(t, err)
+
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"2025-03-01T18:30:00Z"}, export.Row(columns, attendee, time.UTC))
+	assert.Equal(t, []string{"2025-03-02T00:00:00+05:30"}, export.Row(columns, attendee, kolkata))
+}
+
+func TestCSVWriter(t *testing.T) {
+	columns, err := export.ParseColumns("fullName,designation")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(export.FormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(export.Headers(columns)))
+	require.NoError(t, w.Write(export.Row(columns, attendee, time.UTC)))
+	require.NoError(t, w.Close())
+
+	body := buf.String()
+	assert.True(t, strings.HasPrefix(body, "\ufeff"), "CSV should start with a UTF-8 BOM")
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(body, "\ufeff"))).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Full Name", "Designation"},
+		{"Zoë Ångström", "'=HYPERLINK(\"http://evil\")"},
+	}, records)
+}
+
+func TestXLSXWriter(t *testing.T) {
+	columns, err := export.ParseColumns("fullName,email")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(export.FormatXLSX, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(export.Headers(columns)))
+	require.NoError(t, w.Write(export.Row(columns, attendee, time.UTC)))
+	require.NoError(t, w.Write([]string{"<Tom & Jerry>\x00", ""}))
+	require.NoError(t, w.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		parts[f.Name] = string(data)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		assert.Contains(t, parts, name)
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, "Zoë Ångström")
+	assert.Contains(t, sheet, "&lt;Tom &amp; Jerry&gt;")
+	assert.NotContains(t, sheet, "\x00")
+	assert.Equal(t, 3, strings.Count(sheet, "<row>"))
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	_, err := export.NewWriter("pdf", io.Discard)
+	assert.Error(t, err)
+}
@@ -44,15 +44,30 @@ func InitializeFirestore(cfg *config.Config) error {
 	return nil
 }
 
+// GetClientDoc returns the document under which an event's attendees,
+// speakers and sessions are stored.
+func GetClientDoc(clientID string) *firestore.DocumentRef {
+	return Client.Collection("clients").Doc(clientID)
+}
+
 func GetAttendeesCollection() *firestore.CollectionRef {
-	return Client.Collection("clients").Doc(ClientID).Collection("attendees")
+	return GetClientDoc(ClientID).Collection("attendees")
 }
 
 func GetSpeakersCollection() *firestore.CollectionRef {
-	return Client.Collection("clients").Doc(ClientID).Collection("speakers")
+	return GetClientDoc(ClientID).Collection("speakers")
 }
 
 func GetSessionsCollection() *firestore.CollectionRef {
-	return Client.Collection("clients").Doc(ClientID).Collection("sessions")
+	return GetClientDoc(ClientID).Collection("sessions")
 }
 
+func GetAdminsCollection() *firestore.CollectionRef {
+	return GetClientDoc(ClientID).Collection("admins")
+}
+
+// GetSettingsCollection holds one document per event-wide setting, such as
+// the email domain policy.
+func GetSettingsCollection() *firestore.CollectionRef {
+	return GetClientDoc(ClientID).Collection("settings")
+}
@@ -3,9 +3,14 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/auth"
 	"event-registration-backend/config"
+	"event-registration-backend/events"
 	"event-registration-backend/firestore"
 	"event-registration-backend/models"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -15,7 +20,10 @@ import (
 
 var jwtSecret = []byte("your-secret-key-change-in-production")
 
+// LoginRequest authenticates either a named admin created with eventctl
+// (Username set) or the shared ADMIN_PASSWORD (Username empty).
 type LoginRequest struct {
+	Username string `json:"username,omitempty"`
 	Password string `json:"password"`
 }
 
@@ -25,36 +33,50 @@ type LoginResponse struct {
 
 func AdminLogin(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
 
 	var req LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, maxLoginBytes) {
 		return
 	}
 
-	cfg := config.LoadConfig()
-	if req.Password != cfg.AdminPassword {
-		http.Error(w, "Invalid password", http.StatusUnauthorized)
-		return
+	subject := "admin"
+	if req.Username != "" {
+		// No stored admin can have an invalid name, and some, such as ones
+		// with a '/', are not even valid document IDs
+		if auth.ValidateUsername(req.Username) != nil {
+			apierror.Write(w, http.StatusUnauthorized, apierror.CodeInvalidCredentials, "Invalid username or password")
+			return
+		}
+		ctx, cancel := storageContext(r)
+		defer cancel()
+		err := auth.VerifyAdmin(ctx, firestore.GetAdminsCollection(), req.Username, req.Password)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			apierror.Write(w, http.StatusUnauthorized, apierror.CodeInvalidCredentials, "Invalid username or password")
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, "Failed to verify credentials", err)
+			return
+		}
+		subject = req.Username
+	} else {
+		cfg := config.LoadConfig()
+		if req.Password != cfg.AdminPassword {
+			apierror.Write(w, http.StatusUnauthorized, apierror.CodeInvalidCredentials, "Invalid password")
+			return
+		}
 	}
 
 	// Generate JWT token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"admin": true,
+		"sub":   subject,
 		"exp":   time.Now().Add(time.Hour * 24).Unix(),
 	})
 
 	tokenString, err := token.SignedString(jwtSecret)
 	if err != nil {
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		apierror.Internal(w, r, "Failed to generate token", err)
 		return
 	}
 
@@ -63,32 +85,14 @@ func AdminLogin(w http.ResponseWriter, r *http.Request) {
 
 func AdminAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
+			apierror.Write(w, http.StatusUnauthorized, apierror.CodeUnauthorized, "Missing authorization header")
 			return
 		}
 
-		tokenString := authHeader
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			tokenString = authHeader[7:]
-		}
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+		if _, err := parseAdminToken(bearerToken(authHeader)); err != nil {
+			apierror.Write(w, http.StatusUnauthorized, apierror.CodeUnauthorized, "Invalid token")
 			return
 		}
 
@@ -96,39 +100,89 @@ func AdminAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// bearerToken strips an optional "Bearer " prefix from an Authorization
+// header value.
+func bearerToken(authHeader string) string {
+	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+		return authHeader[7:]
+	}
+	return authHeader
+}
+
+// parseAdminToken validates an admin JWT issued by AdminLogin and returns
+// its expiry time.
+func parseAdminToken(tokenString string) (time.Time, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !token.Valid {
+		return time.Time{}, errors.New("invalid token")
+	}
+
+	exp, err := token.Claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return time.Time{}, errors.New("token has no expiry")
+	}
+	return exp.Time, nil
+}
+
+// GetAttendees returns one page of attendees. See parseAttendeeQuery for
+// the supported filter, sort and paging parameters.
 func GetAttendees(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	ctx := context.Background()
-	attendeesRef := firestore.GetAttendeesCollection()
+	q, err := parseAttendeeQuery(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error())
+		return
+	}
 
-	var attendees []models.Attendee
-	iter := attendeesRef.Documents(ctx)
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	query, err := q.page(firestore.GetAttendeesCollection())
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid cursor")
+		return
+	}
+
+	page := models.AttendeePage{Attendees: []models.Attendee{}}
+	iter := query.Documents(ctx)
+	defer iter.Stop()
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			http.Error(w, "Failed to fetch attendees: "+err.Error(), http.StatusInternalServerError)
+			writeStorageError(w, r, "Failed to fetch attendees", err)
 			return
 		}
 
+		if len(page.Attendees) == q.Limit {
+			last := page.Attendees[len(page.Attendees)-1]
+			page.NextCursor = q.cursorFor(last.ID, last.FullName, last.CreatedAt)
+			break
+		}
+
 		var attendee models.Attendee
 		if err := doc.DataTo(&attendee); err != nil {
 			continue
 		}
 		attendee.ID = doc.Ref.ID
-		attendees = append(attendees, attendee)
+		page.Attendees = append(page.Attendees, attendee)
 	}
 
-	json.NewEncoder(w).Encode(attendees)
+	json.NewEncoder(w).Encode(page)
 }
 
 func GetStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	ctx := context.Background()
+	ctx, cancel := bulkStorageContext(w, r)
+	defer cancel()
 	attendeesRef := firestore.GetAttendeesCollection()
 
 	designationCount := make(map[string]int)
@@ -139,12 +193,12 @@ func GetStats(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 		if err != nil {
-			http.Error(w, "Failed to fetch stats: "+err.Error(), http.StatusInternalServerError)
+			writeStorageError(w, r, "Failed to fetch stats", err)
 			return
 		}
 
 		var attendee models.Attendee
-		if err := doc.DataTo(&attendee); err != nil {
+		if err := doc.DataTo(&attendee); err != nil || attendee.Status == models.StatusPendingVerification {
 			continue
 		}
 		designationCount[attendee.Designation]++
@@ -160,47 +214,57 @@ type SpeakerRequest struct {
 	PhotoURL string `json:"photoURL"`
 }
 
+// validateSpeakerRequest holds the rules shared by AddUpdateSpeaker and the
+// speaker bulk import. It returns the normalized speaker to store.
+func validateSpeakerRequest(req SpeakerRequest) (models.Speaker, error) {
+	speaker := models.Speaker{
+		ID:            req.ID,
+		Name:          req.Name,
+		Bio:           req.Bio,
+		PhotoURL:      req.PhotoURL,
+		SchemaVersion: models.SchemaVersion,
+	}
+	speaker.Normalize()
+	return speaker, speaker.Validate()
+}
+
 func AddUpdateSpeaker(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var req SpeakerRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, maxAdminJSONBytes) {
 		return
 	}
 
-	if req.Name == "" {
-		http.Error(w, "Name is required", http.StatusBadRequest)
+	speaker, err := validateSpeakerRequest(req)
+	if err != nil {
+		writeValidationError(w, "Invalid speaker", err)
 		return
 	}
 
-	ctx := context.Background()
+	ctx, cancel := storageContext(r)
+	defer cancel()
 	speakersRef := firestore.GetSpeakersCollection()
 
-	speaker := models.Speaker{
-		Name:     req.Name,
-		Bio:      req.Bio,
-		PhotoURL: req.PhotoURL,
-	}
-
-	if req.ID != "" {
+	if speaker.ID != "" {
 		// Update existing speaker
-		_, err := speakersRef.Doc(req.ID).Set(ctx, speaker)
+		_, err := speakersRef.Doc(speaker.ID).Set(ctx, speaker)
 		if err != nil {
-			http.Error(w, "Failed to update speaker: "+err.Error(), http.StatusInternalServerError)
+			writeStorageError(w, r, "Failed to update speaker", err)
 			return
 		}
-		speaker.ID = req.ID
 	} else {
 		// Create new speaker
 		docRef, _, err := speakersRef.Add(ctx, speaker)
 		if err != nil {
-			http.Error(w, "Failed to create speaker: "+err.Error(), http.StatusInternalServerError)
+			writeStorageError(w, r, "Failed to create speaker", err)
 			return
 		}
 		speaker.ID = docRef.ID
 	}
 
+	InvalidateAgenda()
+	eventHub.Publish(events.Event{Type: events.TypeSpeakerSaved, Data: speaker})
 	json.NewEncoder(w).Encode(speaker)
 }
 
@@ -212,48 +276,87 @@ type SessionRequest struct {
 	SpeakerID   string `json:"speakerId"`
 }
 
+// validateSessionRequest holds the rules shared by AddUpdateSession and the
+// session bulk import. It returns the normalized session to store.
+func validateSessionRequest(req SessionRequest) (models.Session, error) {
+	session := models.Session{
+		ID:            req.ID,
+		Title:         req.Title,
+		Description:   req.Description,
+		Time:          req.Time,
+		SpeakerID:     req.SpeakerID,
+		SchemaVersion: models.SchemaVersion,
+	}
+	session.Normalize()
+	return session, session.Validate()
+}
+
+// checkSpeakersExist returns, for each of sessions, a validation error if it
+// names a speaker that does not exist. Sessions are joined to their speakers
+// when the agenda is served, so AddUpdateSession and the session import
+// both refuse them.
+func checkSpeakersExist(ctx context.Context, sessions []models.Session) ([]error, error) {
+	speakers, err := fetchSpeakers(ctx, sessions)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]error, len(sessions))
+	for i, session := range sessions {
+		if session.SpeakerID != "" && speakers[session.SpeakerID] == nil {
+			var invalid models.ValidationError
+			invalid.Add("speakerId", fmt.Sprintf("Speaker %q does not exist", session.SpeakerID))
+			problems[i] = invalid.Err()
+		}
+	}
+	return problems, nil
+}
+
 func AddUpdateSession(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var req SessionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, maxAdminJSONBytes) {
 		return
 	}
 
-	if req.Title == "" {
-		http.Error(w, "Title is required", http.StatusBadRequest)
+	session, err := validateSessionRequest(req)
+	if err != nil {
+		writeValidationError(w, "Invalid session", err)
 		return
 	}
 
-	ctx := context.Background()
-	sessionsRef := firestore.GetSessionsCollection()
-
-	session := models.Session{
-		Title:       req.Title,
-		Description: req.Description,
-		Time:        req.Time,
-		SpeakerID:   req.SpeakerID,
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	problems, err := checkSpeakersExist(ctx, []models.Session{session})
+	if err != nil {
+		writeStorageError(w, r, "Failed to look up speaker", err)
+		return
+	}
+	if problems[0] != nil {
+		writeValidationError(w, "Invalid session", problems[0])
+		return
 	}
+	sessionsRef := firestore.GetSessionsCollection()
 
-	if req.ID != "" {
+	if session.ID != "" {
 		// Update existing session
-		_, err := sessionsRef.Doc(req.ID).Set(ctx, session)
+		_, err := sessionsRef.Doc(session.ID).Set(ctx, session)
 		if err != nil {
-			http.Error(w, "Failed to update session: "+err.Error(), http.StatusInternalServerError)
+			writeStorageError(w, r, "Failed to update session", err)
 			return
 		}
-		session.ID = req.ID
 	} else {
 		// Create new session
 		docRef, _, err := sessionsRef.Add(ctx, session)
 		if err != nil {
-			http.Error(w, "Failed to create session: "+err.Error(), http.StatusInternalServerError)
+			writeStorageError(w, r, "Failed to create session", err)
 			return
 		}
 		session.ID = docRef.ID
 	}
 
+	InvalidateAgenda()
+	eventHub.Publish(events.Event{Type: events.TypeSessionSaved, Data: session})
 	json.NewEncoder(w).Encode(session)
 }
 
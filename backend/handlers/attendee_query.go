@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	gcfirestore "cloud.google.com/go/firestore"
+)
+
+const (
+	defaultAttendeePageSize = 50
+	maxAttendeePageSize     = 500
+)
+
+// attendeeQuery is the parsed form of the filter, sort and paging
+// parameters accepted by the admin attendee list.
+type attendeeQuery struct {
+	Limit       int
+	SortField   string
+	Descending  bool
+	Designation string
+	Status      string
+	From        time.Time
+	To          time.Time
+	Cursor      *attendeeCursor
+}
+
+// attendeeCursor marks the last attendee of a page: its value for the sort
+// field and its document ID as a tie-breaker.
+type attendeeCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// parseAttendeeQuery reads the attendee list parameters:
+//
+//	limit        page size, 1-500 (default 50)
+//	sort         createdAt or fullName (default createdAt)
+//	order        asc or desc (default desc for createdAt, asc for fullName)
+//	designation  exact designation match
+//	status       exact status match
+//	from, to     registration date range, RFC 3339 or YYYY-MM-DD; a bare
+//	             "to" date includes that whole day
+//	cursor       nextCursor from the previous page
+func parseAttendeeQuery(values url.Values) (attendeeQuery, error) {
+	q := attendeeQuery{
+		Limit:       defaultAttendeePageSize,
+		SortField:   "createdAt",
+		Designation: values.Get("designation"),
+		Status:      values.Get("status"),
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxAttendeePageSize {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxAttendeePageSize)
+		}
+		q.Limit = n
+	}
+
+	switch sort := values.Get("sort"); sort {
+	case "", "createdAt":
+		q.Descending = true
+	case "fullName":
+		q.SortField = sort
+	default:
+		return q, errors.New("sort must be createdAt or fullName")
+	}
+
+	switch order := values.Get("order"); order {
+	case "":
+	case "asc":
+		q.Descending = false
+	case "desc":
+		q.Descending = true
+	default:
+		return q, errors.New("order must be asc or desc")
+	}
+
+	var err error
+	if q.From, err = parseDateParam(values.Get("from"), false); err != nil {
+		return q, fmt.Errorf("from: %w", err)
+	}
+	if q.To, err = parseDateParam(values.Get("to"), true); err != nil {
+		return q, fmt.Errorf("to: %w", err)
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return q, errors.New("from must be before to")
+	}
+	// Firestore requires the first ordering to be on the field used in a
+	// range filter.
+	if (!q.From.IsZero() || !q.To.IsZero()) && q.SortField != "createdAt" {
+		return q, errors.New("date range filters require sort=createdAt")
+	}
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		c, err := decodeAttendeeCursor(cursor)
+		if err != nil || c.Sort != q.SortField {
+			return q, errors.New("invalid cursor")
+		}
+		q.Cursor = c
+	}
+
+	return q, nil
+}
+
+// parseDateParam accepts RFC 3339 timestamps or plain dates. A plain date
+// used as an exclusive upper bound is moved to the start of the next day.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New("expected RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// filter applies the query's filters and ordering, without paging.
+func (q attendeeQuery) filter(ref *gcfirestore.CollectionRef) gcfirestore.Query {
+	query := ref.Query
+	if q.Designation != "" {
+		query = query.Where("designation", "==", q.Designation)
+	}
+	if q.Status != "" {
+		query = query.Where("status", "==", q.Status)
+	}
+	if !q.From.IsZero() {
+		query = query.Where("createdAt", ">=", q.From)
+	}
+	if !q.To.IsZero() {
+		query = query.Where("createdAt", "<", q.To)
+	}
+
+	direction := gcfirestore.Asc
+	if q.Descending {
+		direction = gcfirestore.Desc
+	}
+	return query.OrderBy(q.SortField, direction).OrderBy(gcfirestore.DocumentID, direction)
+}
+
+// page applies filter plus the cursor, and fetches one extra document so
+// the caller can tell whether another page follows.
+func (q attendeeQuery) page(ref *gcfirestore.CollectionRef) (gcfirestore.Query, error) {
+	query := q.filter(ref)
+	if q.Cursor != nil {
+		value, err := q.Cursor.sortValue()
+		if err != nil {
+			return query, err
+		}
+		query = query.StartAfter(value, q.Cursor.ID)
+	}
+	return query.Limit(q.Limit + 1), nil
+}
+
+// cursorFor builds the cursor pointing just past the given attendee.
+func (q attendeeQuery) cursorFor(id, fullName string, createdAt time.Time) string {
+	c := attendeeCursor{Sort: q.SortField, ID: id}
+	if q.SortField == "fullName" {
+		c.Value = fullName
+	} else {
+		c.Value = createdAt.UTC().Format(time.RFC3339Nano)
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAttendeeCursor(s string) (*attendeeCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c attendeeCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.ID == "" {
+		return nil, errors.New("cursor has no document ID")
+	}
+	if _, err := c.sortValue(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *attendeeCursor) sortValue() (interface{}, error) {
+	if c.Sort == "createdAt" {
+		return time.Parse(time.RFC3339Nano, c.Value)
+	}
+	return c.Value, nil
+}
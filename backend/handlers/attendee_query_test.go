@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttendeeQuery_Defaults(t *testing.T) {
+	q, err := parseAttendeeQuery(url.Values{})
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultAttendeePageSize, q.Limit)
+	assert.Equal(t, "createdAt", q.SortField)
+	assert.True(t, q.Descending)
+	assert.Nil(t, q.Cursor)
+}
+
+func TestParseAttendeeQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+		check   func(*testing.T, attendeeQuery)
+	}{
+		{
+			name:  "Sort by name ascending by default",
+			query: "sort=fullName",
+			check: func(t *testing.T, q attendeeQuery) {
+				assert.Equal(t, "fullName", q.SortField)
+				assert.False(t, q.Descending)
+			},
+		},
+		{
+			name:  "Explicit order",
+			query: "sort=createdAt&order=asc",
+			check: func(t *testing.T, q attendeeQuery) {
+				assert.False(t, q.Descending)
+			},
+		},
+		{
+			name:  "Filters",
+			query: "designation=Designer&status=registered&limit=10",
+			check: func(t *testing.T, q attendeeQuery) {
+				assert.Equal(t, "Designer", q.Designation)
+				assert.Equal(t, "registered", q.Status)
+				assert.Equal(t, 10, q.Limit)
+			},
+		},
+		{
+			name:  "Date range includes the whole end day",
+			query: "from=2025-01-01&to=2025-01-31",
+			check: func(t *testing.T, q attendeeQuery) {
+				assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), q.From)
+				assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), q.To)
+			},
+		},
+		{
+			name:  "RFC 3339 bounds are used as given",
+			query: "to=2025-01-31T12:00:00Z",
+			check: func(t *testing.T, q attendeeQuery) {
+				assert.Equal(t, time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC), q.To)
+			},
+		},
+		{name: "Limit too large", query: "limit=1000", wantErr: true},
+		{name: "Limit not a number", query: "limit=ten", wantErr: true},
+		{name: "Unknown sort field", query: "sort=email", wantErr: true},
+		{name: "Unknown order", query: "order=sideways", wantErr: true},
+		{name: "Malformed date", query: "from=yesterday", wantErr: true},
+		{name: "Empty range", query: "from=2025-02-01&to=2025-01-01", wantErr: true},
+		{name: "Range with name sort", query: "sort=fullName&from=2025-01-01", wantErr: true},
+		{name: "Garbage cursor", query: "cursor=not-a-cursor", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			q, err := parseAttendeeQuery(values)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, q)
+		})
+	}
+}
+
+func TestAttendeeCursor_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 3, 14, 9, 26, 53, 589793000, time.UTC)
+
+	q, err := parseAttendeeQuery(url.Values{})
+	require.NoError(t, err)
+	cursor := q.cursorFor("abc123", "Jane Doe", createdAt)
+
+	next, err := parseAttendeeQuery(url.Values{"cursor": {cursor}})
+	require.NoError(t, err)
+	require.NotNil(t, next.Cursor)
+	assert.Equal(t, "abc123", next.Cursor.ID)
+	value, err := next.Cursor.sortValue()
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(value.(time.Time)))
+
+	// A cursor is only valid for the sort order that produced it.
+	_, err = parseAttendeeQuery(url.Values{"cursor": {cursor}, "sort": {"fullName"}})
+	assert.Error(t, err)
+}
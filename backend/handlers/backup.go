@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/backup"
+	"event-registration-backend/events"
+	"event-registration-backend/firestore"
+	"event-registration-backend/requestid"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const maxRestoreBytes = 64 << 20
+
+// BackupEvent downloads every attendee, speaker and session of the event,
+// with its settings and migration ledger, as a versioned JSON archive.
+func BackupEvent(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := bulkStorageContext(w, r)
+	defer cancel()
+	archive, err := backup.Create(ctx, backup.NewFirestoreStore(firestore.Client, firestore.ClientID), firestore.ClientID)
+	if err != nil {
+		writeStorageError(w, r, "Failed to create backup", err)
+		return
+	}
+
+	filename := fmt.Sprintf("event-backup-%s.json", archive.CreatedAt.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	if err := backup.Write(w, archive); err != nil {
+		log.Printf("request %s: Failed to write backup: %v", requestid.FromContext(r.Context()), err)
+	}
+}
+
+// RestoreIncomplete is the error body of a restore that failed after writing
+// some documents. Restored counts them per collection; sending the same
+// archive again with resume=true finishes the restore.
+type RestoreIncomplete struct {
+	apierror.Envelope
+	Restored backup.RestoreResult `json:"restored"`
+}
+
+// RestoreEvent loads an archive produced by BackupEvent into the event.
+// The optional collections parameter (e.g. "speakers,sessions") limits what
+// is restored; every restored collection must currently be empty. With
+// resume=true it finishes a restore of the same archive that failed partway.
+func RestoreEvent(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	archive, err := backup.Read(http.MaxBytesReader(w, r.Body, maxRestoreBytes))
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Invalid archive: "+err.Error())
+		return
+	}
+
+	var collections []string
+	if value := r.URL.Query().Get("collections"); value != "" {
+		collections = strings.Split(value, ",")
+	}
+
+	restore := backup.Restore
+	if r.URL.Query().Get("resume") == "true" {
+		restore = backup.Resume
+	}
+
+	ctx, cancel := bulkWriteContext(w, r)
+	defer cancel()
+	result, err := restore(ctx, backup.NewFirestoreStore(firestore.Client, firestore.ClientID), archive, collections)
+	if len(result) > 0 {
+		attendeeCount.invalidate()
+		InvalidateAgenda()
+		emailDomainPolicy.invalidate()
+		eventHub.Publish(events.Event{Type: events.TypeImport, Data: map[string]interface{}{"kind": "restore", "restored": result}})
+	}
+	if errors.Is(err, backup.ErrUnknownCollection) {
+		apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, err.Error())
+		return
+	}
+	if errors.Is(err, backup.ErrNotEmpty) {
+		apierror.Write(w, http.StatusConflict, apierror.CodeConflict, err.Error())
+		return
+	}
+	if err != nil && len(result) > 0 {
+		writeRestoreIncomplete(w, r, result, err)
+		return
+	}
+	if err != nil {
+		writeStorageError(w, r, "Failed to restore backup", err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeRestoreIncomplete replies to a restore that failed after writing
+// some documents. A plain retry would be refused because the target is no
+// longer empty, so the reply says what was written and how to finish.
+func writeRestoreIncomplete(w http.ResponseWriter, r *http.Request, result backup.RestoreResult, err error) {
+	id := requestid.FromContext(r.Context())
+	log.Printf("request %s: %s %s: restore failed after writing %v: %v", id, r.Method, r.URL.Path, result, err)
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(RestoreIncomplete{
+		Envelope: apierror.Envelope{
+			Code:      apierror.CodeRestoreIncomplete,
+			Message:   "The restore failed after writing some documents; send the same archive again with resume=true to finish it",
+			RequestID: id,
+		},
+		Restored: result,
+	})
+}
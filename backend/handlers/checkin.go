@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/checkin"
+	"event-registration-backend/events"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	gcfirestore "cloud.google.com/go/firestore"
+)
+
+// checkInRetryDelay is how long WatchCheckIns waits before listening again
+// after the listener fails.
+const checkInRetryDelay = 5 * time.Second
+
+// CheckInRequest identifies the attendee to check in by ID or by email.
+type CheckInRequest struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+// CheckInAttendee marks a registered attendee as arrived at the event.
+// Checking in twice succeeds and keeps the first time.
+func CheckInAttendee(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req CheckInRequest
+	if !decodeJSON(w, r, &req, maxAdminJSONBytes) {
+		return
+	}
+	if (req.ID == "") == (req.Email == "") {
+		apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Exactly one of id or email is required")
+		return
+	}
+
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	attendee, err := checkin.CheckIn(ctx, firestore.Client, firestore.GetAttendeesCollection(), req.ID, req.Email)
+	if errors.Is(err, checkin.ErrNotFound) {
+		apierror.Write(w, http.StatusNotFound, apierror.CodeNotFound, "No attendee matches this ID or email")
+		return
+	}
+	if errors.Is(err, checkin.ErrNotRegistered) {
+		apierror.Write(w, http.StatusConflict, apierror.CodeConflict, "This attendee has not confirmed their registration")
+		return
+	}
+	if err != nil {
+		writeStorageError(w, r, "Failed to check in attendee", err)
+		return
+	}
+
+	publishCheckIn(attendee)
+	json.NewEncoder(w).Encode(attendee)
+}
+
+// checkIns remembers the check-ins already published, since WatchCheckIns
+// also sees the ones this server made.
+var checkIns = struct {
+	mu        sync.Mutex
+	published map[string]time.Time
+}{published: make(map[string]time.Time)}
+
+// publishCheckIn tells the live feeds that attendee has arrived, once per
+// check-in.
+func publishCheckIn(attendee models.Attendee) {
+	if attendee.CheckedInAt == nil {
+		return
+	}
+	// Firestore keeps microseconds, so the listener sees a truncated time
+	checkedInAt := attendee.CheckedInAt.Truncate(time.Microsecond)
+	checkIns.mu.Lock()
+	at, seen := checkIns.published[attendee.ID]
+	if seen && at.Equal(checkedInAt) {
+		checkIns.mu.Unlock()
+		return
+	}
+	checkIns.published[attendee.ID] = checkedInAt
+	checkIns.mu.Unlock()
+
+	eventHub.Publish(events.Event{Type: events.TypeCheckIn, Data: attendee})
+}
+
+// WatchCheckIns publishes check-ins made after it starts, including those
+// made outside this server such as with eventctl, until ctx is done.
+func WatchCheckIns(ctx context.Context) {
+	since := time.Now()
+	for {
+		err := watchCheckIns(ctx, since)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Check-in listener failed, retrying: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(checkInRetryDelay):
+		}
+	}
+}
+
+func watchCheckIns(ctx context.Context, since time.Time) error {
+	snapshots := firestore.GetAttendeesCollection().Where("checkedInAt", ">", since).Snapshots(ctx)
+	defer snapshots.Stop()
+	for {
+		snap, err := snapshots.Next()
+		if err != nil {
+			return err
+		}
+		for _, change := range snap.Changes {
+			if change.Kind == gcfirestore.DocumentRemoved {
+				continue
+			}
+			var attendee models.Attendee
+			if err := change.Doc.DataTo(&attendee); err != nil {
+				continue
+			}
+			attendee.ID = change.Doc.Ref.ID
+			publishCheckIn(attendee)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"event-registration-backend/apierror"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckInAttendee_RequiresOneIdentifier(t *testing.T) {
+	for _, body := range []string{`{}`, `{"id":"a1","email":"jane@example.com"}`} {
+		t.Run(body, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/check-in", strings.NewReader(body))
+			rr := httptest.NewRecorder()
+			CheckInAttendee(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			var envelope apierror.Envelope
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&envelope))
+			assert.Equal(t, apierror.CodeInvalidRequest, envelope.Code)
+		})
+	}
+}
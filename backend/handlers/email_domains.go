@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/emaildomain"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// emailDomainPolicyTTL bounds how long other server instances keep
+// accepting a domain after an admin blocks it.
+const emailDomainPolicyTTL = 10 * time.Second
+
+// EmailDomainsRequest replaces the email domain policy.
+type EmailDomainsRequest struct {
+	Blocked     []string `json:"blocked"`
+	Allowed     []string `json:"allowed"`
+	AllowedOnly bool     `json:"allowedOnly"`
+}
+
+// policyCache memoizes the email domain policy for ttl, so registrations
+// do not each read it from storage.
+type policyCache struct {
+	mu      sync.Mutex
+	policy  models.EmailDomainPolicy
+	expires time.Time
+	ttl     time.Duration
+	load    func(ctx context.Context) (models.EmailDomainPolicy, error)
+}
+
+var emailDomainPolicy = &policyCache{ttl: emailDomainPolicyTTL, load: loadEmailDomainPolicy}
+
+func (c *policyCache) get(ctx context.Context) (models.EmailDomainPolicy, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.expires) {
+		return c.policy, nil
+	}
+	policy, err := c.load(ctx)
+	if err != nil {
+		return models.EmailDomainPolicy{}, err
+	}
+	c.policy = policy
+	c.expires = time.Now().Add(c.ttl)
+	return policy, nil
+}
+
+// invalidate makes the next get reload the policy.
+func (c *policyCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires = time.Time{}
+}
+
+func (c *policyCache) set(policy models.EmailDomainPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = policy
+	c.expires = time.Now().Add(c.ttl)
+}
+
+// loadEmailDomainPolicy reads the stored policy. An event that has never
+// saved one gets the empty policy, which only rejects disposable domains.
+func loadEmailDomainPolicy(ctx context.Context) (models.EmailDomainPolicy, error) {
+	policy := models.EmailDomainPolicy{Blocked: []string{}, Allowed: []string{}}
+	doc, err := firestore.GetSettingsCollection().Doc(models.EmailDomainPolicyID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return policy, nil
+	}
+	if err != nil {
+		return policy, err
+	}
+	if err := doc.DataTo(&policy); err != nil {
+		return policy, err
+	}
+	policy.Normalize()
+	return policy, nil
+}
+
+// checkEmailDomain replies with a validation error and returns false when
+// email may not register under the event's domain policy.
+func checkEmailDomain(w http.ResponseWriter, r *http.Request, email string) bool {
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	policy, err := emailDomainPolicy.get(ctx)
+	if err != nil {
+		writeStorageError(w, r, "Failed to load email domain policy", err)
+		return false
+	}
+
+	var message string
+	switch err := emaildomain.Check(policy, email); {
+	case err == nil:
+		return true
+	case errors.Is(err, emaildomain.ErrDisposable):
+		message = "Disposable email addresses are not accepted; please use a permanent address"
+	case errors.Is(err, emaildomain.ErrNotAllowed):
+		message = "This event is only open to addresses from invited organizations"
+	default:
+		message = "Registrations from this email domain are not accepted"
+	}
+	apierror.WriteFields(w, http.StatusBadRequest, apierror.CodeValidationFailed, "Invalid registration",
+		map[string]string{"email": message})
+	return false
+}
+
+// GetEmailDomains returns the email domain policy.
+func GetEmailDomains(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	policy, err := loadEmailDomainPolicy(ctx)
+	if err != nil {
+		writeStorageError(w, r, "Failed to load email domain policy", err)
+		return
+	}
+	json.NewEncoder(w).Encode(policy)
+}
+
+// UpdateEmailDomains replaces the email domain policy. It applies to new
+// registrations only; existing attendees are not re-checked.
+func UpdateEmailDomains(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req EmailDomainsRequest
+	if !decodeJSON(w, r, &req, maxAdminJSONBytes) {
+		return
+	}
+
+	now := time.Now()
+	policy := models.EmailDomainPolicy{
+		Blocked:     req.Blocked,
+		Allowed:     req.Allowed,
+		AllowedOnly: req.AllowedOnly,
+		UpdatedAt:   &now,
+	}
+	policy.Normalize()
+	if err := policy.Validate(); err != nil {
+		writeValidationError(w, "Invalid email domain policy", err)
+		return
+	}
+
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	if _, err := firestore.GetSettingsCollection().Doc(models.EmailDomainPolicyID).Set(ctx, policy); err != nil {
+		writeStorageError(w, r, "Failed to save email domain policy", err)
+		return
+	}
+
+	emailDomainPolicy.set(policy)
+	json.NewEncoder(w).Encode(policy)
+}
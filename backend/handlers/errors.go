@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/models"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Request body limits for JSON endpoints.
+const (
+	maxRegisterBytes  = 16 << 10
+	maxLoginBytes     = 4 << 10
+	maxAdminJSONBytes = 64 << 10
+)
+
+// writeValidationError replies 400 validation_failed, listing each invalid
+// field when err is a models.ValidationError.
+func writeValidationError(w http.ResponseWriter, message string, err error) {
+	var invalid *models.ValidationError
+	if errors.As(err, &invalid) {
+		apierror.WriteFields(w, http.StatusBadRequest, apierror.CodeValidationFailed, message, invalid.Fields)
+		return
+	}
+	apierror.Write(w, http.StatusBadRequest, apierror.CodeValidationFailed, err.Error())
+}
+
+// decodeJSON reads a single JSON object from the request body into v. The
+// body may be at most maxBytes long and may only contain fields v declares.
+// On failure it replies with the matching error and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}, maxBytes int64) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil && dec.Decode(&json.RawMessage{}) != io.EOF {
+		err = errors.New("trailing data")
+	}
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		apierror.Write(w, http.StatusRequestEntityTooLarge, apierror.CodeRequestTooLarge,
+			fmt.Sprintf("Request body must be at most %d bytes", maxBytes))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		apierror.WriteFields(w, http.StatusBadRequest, apierror.CodeValidationFailed, "Request has unknown fields",
+			map[string]string{field: "Unknown field"})
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		apierror.WriteFields(w, http.StatusBadRequest, apierror.CodeValidationFailed, "Request has fields of the wrong type",
+			map[string]string{typeErr.Field: "Must be a " + typeErr.Type.Kind().String()})
+	default:
+		apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Request body must be a single JSON object")
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/firestore"
+	"event-registration-backend/middleware"
+	"event-registration-backend/requestid"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	gcfirestore "cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// leakedDetail stands in for the project names, document paths and query
+// details that storage errors carry.
+const leakedDetail = "projects/secret-project/databases/(default) index users_by_email"
+
+func init() {
+	// The shared count feed keeps running after the test that starts it and
+	// reloads on every registration event later tests publish. Without
+	// Firestore that reload would dereference a nil client.
+	load := attendeeCount.load
+	attendeeCount.load = func(ctx context.Context) (int64, error) {
+		if firestore.Client == nil {
+			return 0, errors.New("firestore is not initialized")
+		}
+		return load(ctx)
+	}
+}
+
+// useFailingFirestore points the firestore package at a fake server that
+// fails every call with leakedDetail, for the duration of the test.
+func useFailingFirestore(t *testing.T) {
+	useFakeFirestore(t, func(interface{}, grpc.ServerStream) error {
+		return status.Error(codes.PermissionDenied, leakedDetail)
+	})
+}
+
+// useFakeFirestore points the firestore package at a server that answers
+// every call with handle, for the duration of the test.
+func useFakeFirestore(t *testing.T, handle grpc.StreamHandler) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(handle))
+	go srv.Serve(lis)
+
+	t.Setenv("FIRESTORE_EMULATOR_HOST", lis.Addr().String())
+	client, err := gcfirestore.NewClient(context.Background(), "secret-project")
+	require.NoError(t, err)
+
+	prevClient, prevID := firestore.Client, firestore.ClientID
+	firestore.Client, firestore.ClientID = client, "test-event"
+	t.Cleanup(func() {
+		firestore.Client, firestore.ClientID = prevClient, prevID
+		client.Close()
+		srv.Stop()
+	})
+}
+
+func TestPublicRoutes_DoNotLeakInternalErrors(t *testing.T) {
+	useFailingFirestore(t)
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		body    string
+	}{
+		{name: "sessions", handler: GetSessions, method: "GET"},
+		{name: "speakers", handler: GetSpeakers, method: "GET"},
+		{name: "attendee count", handler: GetAttendeeCount, method: "GET"},
+		{name: "attendee count stream", handler: StreamAttendeeCount, method: "GET"},
+		{name: "register", handler: RegisterAttendee, method: "POST",
+			body: `{"fullName":"Ada Lovelace","email":"ada@example.com","designation":"Engineer"}`},
+		{name: "named admin login", handler: AdminLogin, method: "POST",
+			body: `{"username":"ada","password":"correct horse battery"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			InvalidateAgenda()
+			attendeeCount.invalidate()
+			logs.Reset()
+
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			middleware.RequestID(tt.handler).ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.NotContains(t, w.Body.String(), "secret-project")
+			assert.NotContains(t, w.Body.String(), "rpc error")
+
+			var envelope apierror.Envelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			assert.Equal(t, apierror.CodeInternal, envelope.Code)
+			id := w.Header().Get(requestid.Header)
+			require.NotEmpty(t, id)
+			assert.Equal(t, id, envelope.RequestID)
+
+			// The detail is still available to operators, under the same ID.
+			assert.Contains(t, logs.String(), id)
+			assert.Contains(t, logs.String(), leakedDetail)
+		})
+	}
+}
+
+func TestWriteStorageError(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		retryAfter string
+	}{
+		{"context deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, apierror.CodeTimeout, ""},
+		{"rpc deadline", status.Error(codes.DeadlineExceeded, leakedDetail), http.StatusGatewayTimeout, apierror.CodeTimeout, ""},
+		{"unavailable", status.Error(codes.Unavailable, leakedDetail), http.StatusServiceUnavailable, apierror.CodeUnavailable, "5"},
+		{"quota", status.Error(codes.ResourceExhausted, leakedDetail), http.StatusServiceUnavailable, apierror.CodeUnavailable, "5"},
+		{"contention", status.Error(codes.Aborted, leakedDetail), http.StatusServiceUnavailable, apierror.CodeUnavailable, "5"},
+		{"other", status.Error(codes.PermissionDenied, leakedDetail), http.StatusInternalServerError, apierror.CodeInternal, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeStorageError(w, httptest.NewRequest("GET", "/", nil), "Failed to fetch", tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+			assert.NotContains(t, w.Body.String(), "secret-project")
+			var envelope apierror.Envelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			assert.Equal(t, tt.code, envelope.Code)
+		})
+	}
+
+	t.Run("client gone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		writeStorageError(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx), "Failed to fetch", context.Canceled)
+		assert.Empty(t, w.Body.String())
+	})
+}
+
+func TestStorageTimeout(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	useFakeFirestore(t, func(interface{}, grpc.ServerStream) error {
+		<-stop
+		return nil
+	})
+	prev := Timeouts
+	t.Cleanup(func() { Timeouts = prev })
+	Timeouts.Request = 50 * time.Millisecond
+
+	w := httptest.NewRecorder()
+	GetSpeakers(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	var envelope apierror.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, apierror.CodeTimeout, envelope.Code)
+}
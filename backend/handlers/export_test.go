@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestExportAttendees_NoMatches(t *testing.T) {
+	useFakeFirestore(t, func(_ interface{}, stream grpc.ServerStream) error {
+		var req firestorepb.RunQueryRequest
+		if err := stream.RecvMsg(&req); err != nil {
+			return err
+		}
+		return stream.SendMsg(&firestorepb.RunQueryResponse{ReadTime: timestamppb.Now()})
+	})
+
+	w := httptest.NewRecorder()
+	require.NotPanics(t, func() {
+		ExportAttendees(w, httptest.NewRequest("GET", "/api/admin/attendees/export?columns=fullName,email", nil))
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "\ufeffFull Name,Email\n", w.Body.String(), "only the header row")
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"event-registration-backend/apierror"
+	"event-registration-backend/middleware"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	feedWriteWait  = 10 * time.Second
+	feedPongWait   = 60 * time.Second
+	feedPingPeriod = feedPongWait * 9 / 10
+)
+
+// FeedOriginAllowed reports whether a page from another origin may open
+// the admin feed. main sets it from the CORS configuration.
+var FeedOriginAllowed = func(origin string) bool { return false }
+
+var feedUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// WebSockets are not subject to CORS, so the origin is checked here
+	// against the same allow-list. Clients other than browsers send no
+	// Origin and are let through to token authentication.
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || middleware.SameOrigin(r, origin) || FeedOriginAllowed(origin)
+	},
+	Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		apierror.Write(w, status, apierror.CodeInvalidRequest, reason.Error())
+	},
+}
+
+// AdminFeed upgrades to a WebSocket and pushes every roster and agenda
+// event to the dashboard as JSON. Browsers cannot set headers on WebSocket
+// requests, so the admin JWT may also be passed as the "token" query
+// parameter. The connection is closed when the token expires.
+func AdminFeed(w http.ResponseWriter, r *http.Request) {
+	tokenString := r.URL.Query().Get("token")
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+		tokenString = bearerToken(authHeader)
+	}
+	if tokenString == "" {
+		apierror.Write(w, http.StatusUnauthorized, apierror.CodeUnauthorized, "Missing authorization token")
+		return
+	}
+
+	expiresAt, err := parseAdminToken(tokenString)
+	if err != nil {
+		apierror.Write(w, http.StatusUnauthorized, apierror.CodeUnauthorized, "Invalid token")
+		return
+	}
+
+	conn, err := feedUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied through feedUpgrader.Error.
+		return
+	}
+	defer conn.Close()
+
+	feed, unsubscribe := eventHub.Subscribe(64)
+	defer unsubscribe()
+
+	// The dashboard never sends data, but reading is required to process
+	// pongs and notice when the client goes away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(feedPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(feedPongWait))
+		})
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(feedPingPeriod)
+	defer ping.Stop()
+	expired := time.NewTimer(time.Until(expiresAt))
+	defer expired.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-streams.done():
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(feedWriteWait))
+			return
+		case <-expired.C:
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "token expired"),
+				time.Now().Add(feedWriteWait))
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(feedWriteWait)); err != nil {
+				return
+			}
+		case e, ok := <-feed:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(feedWriteWait))
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-registration-backend/events"
+	"event-registration-backend/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func adminToken(t *testing.T) string {
+	t.Helper()
+	t.Setenv("ADMIN_PASSWORD", "admin123")
+
+	w := httptest.NewRecorder()
+	AdminLogin(w, httptest.NewRequest("POST", "/api/admin/login", bytes.NewBufferString(`{"password":"admin123"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Token
+}
+
+func TestAdminFeed_RequiresToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(AdminFeed))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	for _, query := range []string{"", "?token=invalid"} {
+		_, resp, err := websocket.DefaultDialer.Dial(url+query, nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestAdminFeed_PushesEvents(t *testing.T) {
+	token := adminToken(t)
+
+	server := httptest.NewServer(http.HandlerFunc(AdminFeed))
+	defer server.Close()
+
+	subscribers := eventHub.Subscribers()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?token="+token, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Wait for the handler to subscribe before publishing.
+	require.Eventually(t, func() bool { return eventHub.Subscribers() > subscribers }, time.Second, 10*time.Millisecond)
+	eventHub.Publish(events.Event{Type: events.TypeRegistration, Data: map[string]string{"email": "john@example.com"}})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var e struct {
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	require.NoError(t, conn.ReadJSON(&e))
+	assert.Equal(t, events.TypeRegistration, e.Type)
+	assert.Equal(t, "john@example.com", e.Data["email"])
+}
+
+func TestAdminFeed_ChecksOrigin(t *testing.T) {
+	prev := FeedOriginAllowed
+	t.Cleanup(func() { FeedOriginAllowed = prev })
+	FeedOriginAllowed = func(origin string) bool { return origin == "https://dashboard.example.com" }
+
+	server := httptest.NewServer(http.HandlerFunc(AdminFeed))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?token=" + adminToken(t)
+
+	for origin, allowed := range map[string]bool{
+		"":                              true,
+		server.URL:                      true,
+		"https://dashboard.example.com": true,
+		"https://evil.example":          false,
+	} {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+		if allowed {
+			require.NoError(t, err, origin)
+			conn.Close()
+		} else {
+			require.Error(t, err, origin)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		}
+	}
+}
+
+func TestAdminFeed_ClosesOnShutdown(t *testing.T) {
+	prev := streams
+	t.Cleanup(func() { streams = prev })
+	streams = newCloseSignal()
+
+	server := httptest.NewServer(http.HandlerFunc(AdminFeed))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?token="+adminToken(t), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The signal stays closed, so it is seen whenever the handler gets to it
+	CloseStreams()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+}
+
+func TestAdminFeed_PushesCheckIns(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(AdminFeed))
+	defer server.Close()
+
+	subscribers := eventHub.Subscribers()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?token="+adminToken(t), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool { return eventHub.Subscribers() > subscribers }, time.Second, 10*time.Millisecond)
+
+	// The endpoint publishes with full precision and the listener later
+	// sees the time Firestore stored; only the first reaches the feed
+	checkedInAt := time.Date(2026, time.October, 18, 9, 30, 0, 123456789, time.UTC)
+	attendee := models.Attendee{ID: "feed-check-in", Email: "jane@example.com", Status: models.StatusRegistered, CheckedInAt: &checkedInAt}
+	publishCheckIn(attendee)
+	stored := checkedInAt.Truncate(time.Microsecond)
+	attendee.CheckedInAt = &stored
+	publishCheckIn(attendee)
+	eventHub.Publish(events.Event{Type: events.TypeRegistration, Data: map[string]string{"email": "john@example.com"}})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var e struct {
+		Type string          `json:"type"`
+		Data models.Attendee `json:"data"`
+	}
+	require.NoError(t, conn.ReadJSON(&e))
+	assert.Equal(t, events.TypeCheckIn, e.Type)
+	assert.Equal(t, "jane@example.com", e.Data.Email)
+	require.NotNil(t, e.Data.CheckedInAt)
+
+	require.NoError(t, conn.ReadJSON(&e))
+	assert.Equal(t, events.TypeRegistration, e.Type, "a check-in must be published once")
+}
@@ -2,12 +2,15 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"event-registration-backend/apierror"
+	"event-registration-backend/challenge"
 	"event-registration-backend/handlers"
-	"event-registration-backend/middleware"
 	"event-registration-backend/models"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -80,23 +83,30 @@ func TestAdminLogin(t *testing.T) {
 	}
 }
 
-func TestAdminLogin_InvalidJSON(t *testing.T) {
-	req := httptest.NewRequest("POST", "/api/admin/login", bytes.NewBufferString("invalid json"))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
+func TestAdminLogin_InvalidUsername(t *testing.T) {
+	for _, username := range []string{"a/b", "..", "Admin", strings.Repeat("a", 65)} {
+		t.Run(username, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{"username": username, "password": "correct horse battery"})
+			w := httptest.NewRecorder()
 
-	handlers.AdminLogin(w, req)
+			handlers.AdminLogin(w, httptest.NewRequest("POST", "/api/admin/login", bytes.NewBuffer(body)))
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			var envelope apierror.Envelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			assert.Equal(t, apierror.CodeInvalidCredentials, envelope.Code)
+		})
+	}
 }
 
-func TestAdminLogin_OPTIONS(t *testing.T) {
-	req := httptest.NewRequest("OPTIONS", "/api/admin/login", nil)
+func TestAdminLogin_InvalidJSON(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/admin/login", bytes.NewBufferString("invalid json"))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
 	handlers.AdminLogin(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
 func TestAdminAuthMiddleware(t *testing.T) {
@@ -260,15 +270,6 @@ func TestRegisterAttendee_WrongMethod(t *testing.T) {
 	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
 }
 
-func TestRegisterAttendee_OPTIONS(t *testing.T) {
-	req := httptest.NewRequest("OPTIONS", "/api/register", nil)
-	w := httptest.NewRecorder()
-
-	handlers.RegisterAttendee(w, req)
-
-	assert.Equal(t, http.StatusOK, w.Code)
-}
-
 func TestAddUpdateSpeaker_Validation(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -387,18 +388,211 @@ func TestAddUpdateSession_Validation(t *testing.T) {
 	}
 }
 
-func TestCORS_Middleware(t *testing.T) {
-	req := httptest.NewRequest("OPTIONS", "/api/test", nil)
+
+func TestExportAttendees_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Unknown format", query: "format=pdf"},
+		{name: "Unknown column", query: "columns=fullName,password"},
+		{name: "Unknown time zone", query: "tz=Mars/Olympus_Mons"},
+		{name: "Invalid filter", query: "from=yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/admin/attendees/export?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handlers.ExportAttendees(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestImport_InvalidCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+	}{
+		{name: "Attendees unknown column", handler: handlers.ImportAttendees, body: "fullName,phone\nJane,123\n"},
+		{name: "Speakers empty file", handler: handlers.ImportSpeakers, body: ""},
+		{name: "Sessions malformed quoting", handler: handlers.ImportSessions, body: "title\n\"Keynote\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/admin/import?dryRun=true", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "text/csv")
+			w := httptest.NewRecorder()
+
+			tt.handler(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestRegisterAttendee_ValidationErrorEnvelope(t *testing.T) {
+	body, _ := json.Marshal(models.RegisterRequest{Designation: "Developer"})
+	req := httptest.NewRequest("POST", "/api/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
+	handlers.RegisterAttendee(w, req)
 
-	corsHandler := middleware.CORS(nextHandler)
-	corsHandler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var envelope apierror.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, apierror.CodeValidationFailed, envelope.Code)
+	assert.NotEmpty(t, envelope.Message)
+	assert.Equal(t, map[string]string{
+		"fullName": "Full name is required",
+		"email":    "Email is required",
+	}, envelope.Fields)
+}
 
-	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+func TestAdminLogin_ErrorEnvelope(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/admin/login", bytes.NewBufferString(`{"password":"wrong"}`))
+	w := httptest.NewRecorder()
+
+	handlers.AdminLogin(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var envelope apierror.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, apierror.CodeInvalidCredentials, envelope.Code)
+	assert.Empty(t, envelope.Fields)
+}
+
+func TestStrictJSONDecoding(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		body       string
+		wantStatus int
+		wantCode   string
+		wantFields map[string]string
+	}{
+		{
+			name:       "unknown registration field",
+			handler:    handlers.RegisterAttendee,
+			body:       `{"fullName":"Ada","email":"ada@example.com","designation":"Dev","isAdmin":true}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apierror.CodeValidationFailed,
+			wantFields: map[string]string{"isAdmin": "Unknown field"},
+		},
+		{
+			name:       "wrong field type",
+			handler:    handlers.RegisterAttendee,
+			body:       `{"fullName":42,"email":"ada@example.com","designation":"Dev"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apierror.CodeValidationFailed,
+		},
+		{
+			name:       "trailing data",
+			handler:    handlers.RegisterAttendee,
+			body:       `{"fullName":"Ada","email":"ada@example.com","designation":"Dev"}{}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apierror.CodeInvalidRequest,
+		},
+		{
+			name:       "invalid email",
+			handler:    handlers.RegisterAttendee,
+			body:       `{"fullName":"Ada","email":"ada@@example","designation":"Dev"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apierror.CodeValidationFailed,
+			wantFields: map[string]string{"email": "Email must be a valid address such as name@example.com"},
+		},
+		{
+			name:       "oversized registration",
+			handler:    handlers.RegisterAttendee,
+			body:       `{"fullName":"` + strings.Repeat("a", 32<<10) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   apierror.CodeRequestTooLarge,
+		},
+		{
+			name:       "oversized login",
+			handler:    handlers.AdminLogin,
+			body:       `{"password":"` + strings.Repeat("a", 8<<10) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   apierror.CodeRequestTooLarge,
+		},
+		{
+			name:       "unknown speaker field",
+			handler:    handlers.AddUpdateSpeaker,
+			body:       `{"name":"Grace","schemaVersion":9}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apierror.CodeValidationFailed,
+			wantFields: map[string]string{"schemaVersion": "Unknown field"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/register", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			tt.handler(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var envelope apierror.Envelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			assert.Equal(t, tt.wantCode, envelope.Code)
+			if tt.wantFields != nil {
+				assert.Equal(t, tt.wantFields, envelope.Fields)
+			}
+		})
+	}
+}
+
+func TestRegisterAttendee_Honeypot(t *testing.T) {
+	// Nothing is stored, so this works without Firestore
+	body := `{"fullName":"Bot","email":"bot@example.com","designation":"Dev","website":"http://spam.example"}`
+	req := httptest.NewRequest("POST", "/api/v1/register", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	handlers.RegisterAttendee(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.JSONEq(t, `{"message":"Check your email to confirm your registration"}`, w.Body.String())
 }
 
+type stubChallenge struct{ err error }
+
+func (s stubChallenge) Challenge() challenge.Challenge {
+	return challenge.Challenge{Type: "stub"}
+}
+
+func (s stubChallenge) Verify(ctx context.Context, r *http.Request, req models.RegisterRequest) error {
+	return s.err
+}
+
+func TestRegisterAttendee_Challenge(t *testing.T) {
+	t.Cleanup(func() { handlers.RegistrationChallenge = nil })
+
+	w := httptest.NewRecorder()
+	handlers.GetRegistrationChallenge(w, httptest.NewRequest("GET", "/api/v1/register/challenge", nil))
+	assert.JSONEq(t, `{"type":"none"}`, w.Body.String())
+
+	handlers.RegistrationChallenge = stubChallenge{err: challenge.ErrFailed}
+
+	w = httptest.NewRecorder()
+	handlers.GetRegistrationChallenge(w, httptest.NewRequest("GET", "/api/v1/register/challenge", nil))
+	assert.JSONEq(t, `{"type":"stub"}`, w.Body.String())
+
+	body := `{"fullName":"Ada","email":"ada@example.com","designation":"Dev","challenge":"wrong"}`
+	w = httptest.NewRecorder()
+	handlers.RegisterAttendee(w, httptest.NewRequest("POST", "/api/v1/register", bytes.NewBufferString(body)))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var envelope apierror.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, apierror.CodeChallengeFailed, envelope.Code)
+}
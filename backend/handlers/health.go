@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/firestore"
+	"event-registration-backend/migrations"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// readinessTimeout bounds each readiness check, well inside the timeouts
+// of load balancer and container health checks.
+var readinessTimeout = 3 * time.Second
+
+// Health check statuses.
+const (
+	HealthOK   = "ok"
+	HealthFail = "fail"
+)
+
+// HealthResponse is the body of Healthz and Readyz.
+type HealthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the outcome of one readiness check. Error is kept short
+// and free of storage details, since the endpoint is public.
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// readinessCheck reports whether one dependency is usable. Errors are
+// logged in full; only a public error's message reaches the client.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// publicError is a check failure whose message is safe to show.
+type publicError struct{ message string }
+
+func (e publicError) Error() string { return e.message }
+
+var readinessChecks = []readinessCheck{
+	{"storage", pingStorage},
+	{"migrations", checkMigrations},
+}
+
+// pingStorage reads the event's root document, the cheapest call that
+// proves Firestore is reachable with working credentials. The document
+// need not exist.
+func pingStorage(ctx context.Context) error {
+	if firestore.Client == nil {
+		return publicError{"not initialized"}
+	}
+	_, err := firestore.GetClientDoc(firestore.ClientID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+// checkMigrations fails while any migration this build knows about has
+// not been applied to the event.
+func checkMigrations(ctx context.Context) error {
+	if firestore.Client == nil {
+		return publicError{"not initialized"}
+	}
+	ev := migrations.NewEvent(firestore.Client, firestore.ClientID)
+	pending, err := migrations.Pending(ctx, migrations.NewFirestoreLedger(ev), migrations.All())
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		ids := make([]string, len(pending))
+		for i, m := range pending {
+			ids[i] = m.ID
+		}
+		return publicError{"pending: " + strings.Join(ids, ", ")}
+	}
+	return nil
+}
+
+// Healthz reports that the process is up and serving. It checks nothing
+// else, so a storage outage does not get healthy instances restarted.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(HealthResponse{Status: HealthOK})
+}
+
+// Readyz reports whether the instance can serve traffic: storage is
+// reachable and every migration has been applied. It replies 503 with the
+// failing checks otherwise.
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	resp := HealthResponse{Status: HealthOK, Checks: make(map[string]CheckResult, len(readinessChecks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range readinessChecks {
+		wg.Add(1)
+		go func(c readinessCheck) {
+			defer wg.Done()
+			result := runCheck(r, c)
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Checks[c.name] = result
+			if result.Status != HealthOK {
+				resp.Status = HealthFail
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if resp.Status != HealthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func runCheck(r *http.Request, c readinessCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.check(ctx)
+	result := CheckResult{Status: HealthOK, DurationMs: time.Since(start).Milliseconds()}
+	if err == nil {
+		return result
+	}
+
+	log.Printf("Readiness check %s failed: %v", c.name, err)
+	result.Status = HealthFail
+	var public publicError
+	switch {
+	case errors.As(err, &public):
+		result.Error = public.message
+	case errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded:
+		result.Error = "timed out"
+	default:
+		result.Error = "unavailable"
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readyz(t *testing.T) (int, HealthResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	Readyz(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	var resp HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+func useReadinessChecks(t *testing.T, checks ...readinessCheck) {
+	prev := readinessChecks
+	t.Cleanup(func() { readinessChecks = prev })
+	readinessChecks = checks
+}
+
+func TestHealthz(t *testing.T) {
+	w := httptest.NewRecorder()
+	Healthz(w, httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+
+	t.Run("ready", func(t *testing.T) {
+		useReadinessChecks(t, readinessCheck{"storage", ok}, readinessCheck{"migrations", ok})
+		code, resp := readyz(t)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, HealthOK, resp.Status)
+		assert.Equal(t, HealthOK, resp.Checks["storage"].Status)
+		assert.Equal(t, HealthOK, resp.Checks["migrations"].Status)
+	})
+
+	t.Run("failing check", func(t *testing.T) {
+		useReadinessChecks(t,
+			readinessCheck{"storage", ok},
+			readinessCheck{"migrations", func(context.Context) error { return publicError{"pending: 0002_example"} }},
+		)
+		code, resp := readyz(t)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, HealthFail, resp.Status)
+		assert.Equal(t, HealthOK, resp.Checks["storage"].Status)
+		assert.Equal(t, HealthFail, resp.Checks["migrations"].Status)
+		assert.Equal(t, "pending: 0002_example", resp.Checks["migrations"].Error)
+	})
+
+	t.Run("slow check", func(t *testing.T) {
+		prev := readinessTimeout
+		t.Cleanup(func() { readinessTimeout = prev })
+		readinessTimeout = 50 * time.Millisecond
+		useReadinessChecks(t, readinessCheck{"storage", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}})
+		start := time.Now()
+		code, resp := readyz(t)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "timed out", resp.Checks["storage"].Error)
+	})
+
+	t.Run("hides error details", func(t *testing.T) {
+		useReadinessChecks(t, readinessCheck{"storage", func(context.Context) error { return errors.New(leakedDetail) }})
+		_, resp := readyz(t)
+		assert.Equal(t, "unavailable", resp.Checks["storage"].Error)
+	})
+}
+
+func TestReadyz_StorageUnreachable(t *testing.T) {
+	useFailingFirestore(t)
+
+	code, resp := readyz(t)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthFail, resp.Checks["storage"].Status)
+	assert.Equal(t, "unavailable", resp.Checks["storage"].Error)
+	assert.Equal(t, HealthFail, resp.Checks["migrations"].Status)
+}
@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/events"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+	"event-registration-backend/requestid"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	gcfirestore "cloud.google.com/go/firestore"
+)
+
+const (
+	// importBatchSize is Firestore's limit on writes per transaction.
+	importBatchSize = 500
+	maxImportBytes  = 10 << 20
+)
+
+// ImportRowError reports why one CSV row was rejected. Row is the 1-based
+// line number in the file, counting the header.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportResult summarizes a bulk import or its dry run. Preview lists the
+// records that would be written and is only filled in for dry runs.
+type ImportResult struct {
+	Kind     string           `json:"kind"`
+	DryRun   bool             `json:"dryRun"`
+	Rows     int              `json:"rows"`
+	Imported int              `json:"imported"`
+	Errors   []ImportRowError `json:"errors"`
+	Preview  []interface{}    `json:"preview,omitempty"`
+}
+
+// ImportIncomplete is the error body of an import that failed after some
+// of its batches were written. Imported counts the rows saved, which are
+// always the first ones in the file, so a retry should send only the rest.
+type ImportIncomplete struct {
+	apierror.Envelope
+	Rows     int `json:"rows"`
+	Imported int `json:"imported"`
+}
+
+// csvRow is one data row keyed by header name.
+type csvRow struct {
+	line   int
+	fields map[string]string
+}
+
+// importWrite is a document write planned from a valid row.
+type importWrite struct {
+	ref  *gcfirestore.DocumentRef
+	data interface{}
+}
+
+// importPlan turns parsed rows into writes, collecting per-row errors.
+type importPlan func(ctx context.Context, rows []csvRow) ([]importWrite, []ImportRowError, error)
+
+// ImportAttendees bulk-registers attendees from a CSV with the columns
+// fullName, email, designation and optionally createdAt (RFC 3339).
+func ImportAttendees(w http.ResponseWriter, r *http.Request) {
+	runImport(w, r, "attendees", []string{"fullName", "email", "designation", "createdAt"}, planAttendeeImport)
+}
+
+// ImportSpeakers adds or updates speakers from a CSV with the columns id,
+// name, bio and photoURL. Rows with an id update that speaker.
+func ImportSpeakers(w http.ResponseWriter, r *http.Request) {
+	runImport(w, r, "speakers", []string{"id", "name", "bio", "photoURL"}, planSpeakerImport)
+}
+
+// ImportSessions adds or updates sessions from a CSV with the columns id,
+// title, description, time and speakerId. Rows with an id update that
+// session.
+func ImportSessions(w http.ResponseWriter, r *http.Request) {
+	runImport(w, r, "sessions", []string{"id", "title", "description", "time", "speakerId"}, planSessionImport)
+}
+
+// runImport reads a CSV upload, validates every row and, unless dryRun=true
+// is set, writes them in transactions of importBatchSize. Nothing is written
+// if any row is invalid. If storage fails after some batches were written,
+// the reply is an ImportIncomplete. The CSV may be the raw request body or
+// a multipart "file" field.
+func runImport(w http.ResponseWriter, r *http.Request, kind string, columns []string, plan importPlan) {
+	w.Header().Set("Content-Type", "application/json")
+
+	body, err := importBody(w, r)
+	if err != nil {
+		writeUploadError(w, "Invalid upload: ", err)
+		return
+	}
+
+	rows, err := readCSV(body, columns)
+	if err != nil {
+		writeUploadError(w, "Invalid CSV: ", err)
+		return
+	}
+
+	ctx, cancel := bulkWriteContext(w, r)
+	defer cancel()
+	writes, rowErrors, err := plan(ctx, rows)
+	if err != nil {
+		writeStorageError(w, r, "Failed to validate import", err)
+		return
+	}
+
+	result := ImportResult{
+		Kind:   kind,
+		DryRun: r.URL.Query().Get("dryRun") == "true",
+		Rows:   len(rows),
+		Errors: rowErrors,
+	}
+	if result.Errors == nil {
+		result.Errors = []ImportRowError{}
+	}
+
+	if result.DryRun {
+		for _, write := range writes {
+			result.Preview = append(result.Preview, write.data)
+		}
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	if len(rowErrors) > 0 {
+		fields := make(map[string]string, len(rowErrors))
+		for _, rowErr := range rowErrors {
+			fields[fmt.Sprintf("row %d", rowErr.Row)] = rowErr.Message
+		}
+		apierror.WriteFields(w, http.StatusUnprocessableEntity, apierror.CodeValidationFailed,
+			fmt.Sprintf("%d of %d rows are invalid; nothing was imported", len(rowErrors), len(rows)), fields)
+		return
+	}
+
+	var importErr error
+	for start := 0; start < len(writes); start += importBatchSize {
+		batch := writes[start:min(start+importBatchSize, len(writes))]
+		importErr = firestore.Client.RunTransaction(ctx, func(ctx context.Context, tx *gcfirestore.Transaction) error {
+			for _, write := range batch {
+				if err := tx.Set(write.ref, write.data); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if importErr != nil {
+			break
+		}
+		result.Imported += len(batch)
+	}
+	if importErr != nil && result.Imported == 0 {
+		writeStorageError(w, r, "Import failed", importErr)
+		return
+	}
+
+	if kind == "attendees" {
+		attendeeCount.invalidate()
+	} else {
+		InvalidateAgenda()
+	}
+	eventHub.Publish(events.Event{Type: events.TypeImport, Data: map[string]interface{}{"kind": kind, "imported": result.Imported}})
+
+	if importErr != nil {
+		writeImportIncomplete(w, r, result, importErr)
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeImportIncomplete replies to an import that failed after writing
+// some batches. Unlike other storage failures, a blind retry would import
+// those rows twice, so the reply says how many were saved.
+func writeImportIncomplete(w http.ResponseWriter, r *http.Request, result ImportResult, err error) {
+	id := requestid.FromContext(r.Context())
+	log.Printf("request %s: %s %s: import failed after %d of %d rows: %v", id, r.Method, r.URL.Path, result.Imported, result.Rows, err)
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(ImportIncomplete{
+		Envelope: apierror.Envelope{
+			Code: apierror.CodeImportIncomplete,
+			Message: fmt.Sprintf("Only the first %d of %d rows were imported before storage failed; import the remaining rows to finish",
+				result.Imported, result.Rows),
+			RequestID: id,
+		},
+		Rows:     result.Rows,
+		Imported: result.Imported,
+	})
+}
+
+// writeUploadError replies 413 when the upload went over maxImportBytes,
+// whether that showed while parsing the form or reading the CSV, and 400
+// otherwise.
+func writeUploadError(w http.ResponseWriter, prefix string, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		apierror.Write(w, http.StatusRequestEntityTooLarge, apierror.CodeRequestTooLarge,
+			fmt.Sprintf("Request body must be at most %d bytes", maxImportBytes))
+		return
+	}
+	apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, prefix+err.Error())
+}
+
+func importBody(w http.ResponseWriter, r *http.Request) (io.Reader, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, nil
+	}
+
+	if err := r.ParseMultipartForm(maxImportBytes); err != nil {
+		return nil, err
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// readCSV parses a CSV whose header names a subset of columns. Rows are
+// returned keyed by column name; missing columns read as empty strings.
+func readCSV(body io.Reader, columns []string) ([]csvRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		known[c] = true
+	}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !known[name] {
+			return nil, fmt.Errorf("unknown column %q, expected some of %s", name, strings.Join(columns, ", "))
+		}
+		header[i] = name
+	}
+
+	var rows []csvRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := csvRow{line: line, fields: make(map[string]string, len(header))}
+		for i, name := range header {
+			row.fields[name] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func planAttendeeImport(ctx context.Context, rows []csvRow) ([]importWrite, []ImportRowError, error) {
+	existing, err := fetchAllAttendees(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	emails := make(map[string]int, len(existing)+len(rows))
+	for _, a := range existing {
+		emails[a.Email] = 0
+	}
+
+	attendeesRef := firestore.GetAttendeesCollection()
+	var writes []importWrite
+	var rowErrors []ImportRowError
+	for _, row := range rows {
+		req := models.RegisterRequest{
+			FullName:    row.fields["fullName"],
+			Email:       row.fields["email"],
+			Designation: row.fields["designation"],
+		}
+		if err := validateRegisterRequest(&req); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: row.line, Message: err.Error()})
+			continue
+		}
+		if line, ok := emails[req.Email]; ok {
+			message := "Email already registered"
+			if line > 0 {
+				message = fmt.Sprintf("Email duplicates row %d", line)
+			}
+			rowErrors = append(rowErrors, ImportRowError{Row: row.line, Message: message})
+			continue
+		}
+		emails[req.Email] = row.line
+
+		createdAt := time.Now()
+		if value := row.fields["createdAt"]; value != "" {
+			createdAt, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				rowErrors = append(rowErrors, ImportRowError{Row: row.line, Message: "createdAt must be an RFC 3339 timestamp"})
+				continue
+			}
+		}
+
+		ref := attendeesRef.NewDoc()
+		writes = append(writes, importWrite{ref: ref, data: models.Attendee{
+			ID:            ref.ID,
+			FullName:      req.FullName,
+			Email:         req.Email,
+			Designation:   req.Designation,
+			Status:        models.StatusRegistered,
+			CreatedAt:     createdAt,
+			SchemaVersion: models.SchemaVersion,
+		}})
+	}
+	return writes, rowErrors, nil
+}
+
+func planSpeakerImport(ctx context.Context, rows []csvRow) ([]importWrite, []ImportRowError, error) {
+	speakersRef := firestore.GetSpeakersCollection()
+	var writes []importWrite
+	var rowErrors []ImportRowError
+	for _, row := range rows {
+		speaker, err := validateSpeakerRequest(SpeakerRequest{
+			ID:       row.fields["id"],
+			Name:     row.fields["name"],
+			Bio:      row.fields["bio"],
+			PhotoURL: row.fields["photoURL"],
+		})
+		if err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: row.line, Message: err.Error()})
+			continue
+		}
+
+		ref := speakersRef.NewDoc()
+		if speaker.ID != "" {
+			ref = speakersRef.Doc(speaker.ID)
+		}
+		speaker.ID = ref.ID
+		writes = append(writes, importWrite{ref: ref, data: speaker})
+	}
+	return writes, rowErrors, nil
+}
+
+func planSessionImport(ctx context.Context, rows []csvRow) ([]importWrite, []ImportRowError, error) {
+	sessions := make([]models.Session, len(rows))
+	invalid := make([]error, len(rows))
+	for i, row := range rows {
+		sessions[i], invalid[i] = validateSessionRequest(SessionRequest{
+			ID:          row.fields["id"],
+			Title:       row.fields["title"],
+			Description: row.fields["description"],
+			Time:        row.fields["time"],
+			SpeakerID:   row.fields["speakerId"],
+		})
+	}
+
+	var referenced []models.Session
+	var referencedRows []int
+	for i, session := range sessions {
+		if invalid[i] == nil {
+			referenced = append(referenced, session)
+			referencedRows = append(referencedRows, i)
+		}
+	}
+	problems, err := checkSpeakersExist(ctx, referenced)
+	if err != nil {
+		return nil, nil, err
+	}
+	for j, i := range referencedRows {
+		invalid[i] = problems[j]
+	}
+
+	sessionsRef := firestore.GetSessionsCollection()
+	var writes []importWrite
+	var rowErrors []ImportRowError
+	for i, row := range rows {
+		session := sessions[i]
+		if invalid[i] != nil {
+			rowErrors = append(rowErrors, ImportRowError{Row: row.line, Message: invalid[i].Error()})
+			continue
+		}
+
+		ref := sessionsRef.NewDoc()
+		if session.ID != "" {
+			ref = sessionsRef.Doc(session.ID)
+		}
+		session.ID = ref.ID
+		writes = append(writes, importWrite{ref: ref, data: session})
+	}
+	return writes, rowErrors, nil
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"event-registration-backend/apierror"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestReadCSV(t *testing.T) {
+	input := "\ufeffname, bio\nJane Doe,\"Builds \"\"things\"\"\nand more\"\n Ada , Pioneer\n"
+
+	rows, err := readCSV(strings.NewReader(input), []string{"id", "name", "bio", "photoURL"})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	assert.Equal(t, 2, rows[0].line)
+	assert.Equal(t, "Jane Doe", rows[0].fields["name"])
+	assert.Equal(t, "Builds \"things\"\nand more", rows[0].fields["bio"])
+	assert.Equal(t, "", rows[0].fields["id"])
+
+	assert.Equal(t, 4, rows[1].line, "line numbers account for multi-line fields")
+	assert.Equal(t, "Ada", rows[1].fields["name"])
+}
+
+func TestReadCSV_Errors(t *testing.T) {
+	columns := []string{"fullName", "email", "designation"}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Empty file", input: ""},
+		{name: "Unknown column", input: "fullName,password\nJane,secret\n"},
+		{name: "Ragged row", input: "fullName,email\nJane\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readCSV(strings.NewReader(tt.input), columns)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestImport_TooLarge(t *testing.T) {
+	csv := "title,time\n" + strings.Repeat("Keynote,09:00\n", maxImportBytes/14+1)
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, err := mw.CreateFormFile("file", "sessions.csv")
+	require.NoError(t, err)
+	part.Write([]byte(csv))
+	require.NoError(t, mw.Close())
+
+	tests := []struct {
+		name        string
+		body        io.Reader
+		contentType string
+	}{
+		{name: "Raw body", body: strings.NewReader(csv), contentType: "text/csv"},
+		{name: "Multipart", body: &form, contentType: mw.FormDataContentType()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/admin/import/sessions", tt.body)
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			ImportSessions(w, req)
+
+			assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+			var envelope apierror.Envelope
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			assert.Equal(t, apierror.CodeRequestTooLarge, envelope.Code)
+		})
+	}
+}
+
+// useSpeakers fakes a Firestore holding only the speakers with the given
+// IDs, answering the batch reads the agenda and session import make.
+func useSpeakers(t *testing.T, ids ...string) {
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+	useFakeFirestore(t, func(_ interface{}, stream grpc.ServerStream) error {
+		var req firestorepb.BatchGetDocumentsRequest
+		if err := stream.RecvMsg(&req); err != nil {
+			return err
+		}
+		now := timestamppb.Now()
+		for _, name := range req.Documents {
+			resp := &firestorepb.BatchGetDocumentsResponse{ReadTime: now}
+			if known[path.Base(name)] {
+				resp.Result = &firestorepb.BatchGetDocumentsResponse_Found{Found: &firestorepb.Document{
+					Name:       name,
+					Fields:     map[string]*firestorepb.Value{"name": {ValueType: &firestorepb.Value_StringValue{StringValue: path.Base(name)}}},
+					CreateTime: now,
+					UpdateTime: now,
+				}}
+			} else {
+				resp.Result = &firestorepb.BatchGetDocumentsResponse_Missing{Missing: name}
+			}
+			if err := stream.SendMsg(resp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestPlanSessionImport_UnknownSpeaker(t *testing.T) {
+	useSpeakers(t, "grace")
+
+	rows, err := readCSV(strings.NewReader("title,time,speakerId\nKeynote,09:00,grace\nPanel,10:00,nobody\nBreak,10:30,\n,11:00,nobody\n"),
+		[]string{"id", "title", "description", "time", "speakerId"})
+	require.NoError(t, err)
+
+	writes, rowErrors, err := planSessionImport(context.Background(), rows)
+	require.NoError(t, err)
+	assert.Len(t, writes, 2, "sessions with a known speaker or none are kept")
+	require.Len(t, rowErrors, 2)
+	assert.Equal(t, ImportRowError{Row: 3, Message: `Speaker "nobody" does not exist`}, rowErrors[0])
+	assert.Equal(t, 5, rowErrors[1].Row, "invalid rows keep their own error")
+	assert.Contains(t, rowErrors[1].Message, "Title")
+}
+
+func TestAddUpdateSession_UnknownSpeaker(t *testing.T) {
+	useSpeakers(t, "grace")
+
+	w := httptest.NewRecorder()
+	AddUpdateSession(w, httptest.NewRequest("POST", "/api/admin/sessions",
+		strings.NewReader(`{"title":"Panel","time":"10:00","speakerId":"nobody"}`)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var envelope apierror.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, apierror.CodeValidationFailed, envelope.Code)
+	assert.Equal(t, `Speaker "nobody" does not exist`, envelope.Fields["speakerId"])
+}
+
+func TestImport_ReportsRowsWrittenBeforeFailure(t *testing.T) {
+	commits := 0
+	useFakeFirestore(t, func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		switch path.Base(method) {
+		case "BeginTransaction":
+			var req firestorepb.BeginTransactionRequest
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			return stream.SendMsg(&firestorepb.BeginTransactionResponse{Transaction: []byte("tx")})
+		case "Commit":
+			var req firestorepb.CommitRequest
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			if commits++; commits > 1 {
+				return status.Error(codes.PermissionDenied, "denied")
+			}
+			return stream.SendMsg(&firestorepb.CommitResponse{CommitTime: timestamppb.Now()})
+		default:
+			var req firestorepb.RollbackRequest
+			stream.RecvMsg(&req)
+			return stream.SendMsg(&emptypb.Empty{})
+		}
+	})
+
+	csv := "name\n" + strings.Repeat("Speaker\n", importBatchSize+1)
+	w := httptest.NewRecorder()
+	ImportSpeakers(w, httptest.NewRequest("POST", "/api/admin/import/speakers", strings.NewReader(csv)))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var body ImportIncomplete
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, apierror.CodeImportIncomplete, body.Code)
+	assert.Equal(t, importBatchSize+1, body.Rows)
+	assert.Equal(t, importBatchSize, body.Imported, "the first batch was committed")
+}
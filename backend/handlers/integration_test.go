@@ -63,12 +63,12 @@ func TestRegisterAttendee_Integration(t *testing.T) {
 				Email:       "john@example.com",
 				Designation: "Developer",
 			},
-			expectedStatus: http.StatusCreated,
+			expectedStatus: http.StatusAccepted,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
 				var response map[string]string
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				require.NoError(t, err)
-				assert.Equal(t, "Registration successful", response["message"])
+				assert.Equal(t, "Check your email to confirm your registration", response["message"])
 			},
 		},
 		{
@@ -99,15 +99,15 @@ func TestRegisterAttendee_Integration(t *testing.T) {
 
 			// Note: Without proper Firestore mocking, this may return 500
 			// The test validates the HTTP layer and request handling
-			if tt.expectedStatus == http.StatusCreated {
-				assert.True(t, w.Code == http.StatusCreated || w.Code == http.StatusInternalServerError || w.Code == 0)
+			if tt.expectedStatus == http.StatusAccepted {
+				assert.True(t, w.Code == http.StatusAccepted || w.Code == http.StatusInternalServerError || w.Code == 0)
 			} else if tt.expectedStatus == http.StatusConflict {
 				assert.True(t, w.Code == http.StatusConflict || w.Code == http.StatusInternalServerError || w.Code == 0)
 			} else {
 				assert.Equal(t, tt.expectedStatus, w.Code)
 			}
 
-			if tt.checkResponse != nil && w.Code == http.StatusCreated {
+			if tt.checkResponse != nil && w.Code == http.StatusAccepted {
 				tt.checkResponse(t, w)
 			}
 		})
@@ -3,91 +3,312 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/challenge"
 	"event-registration-backend/firestore"
 	"event-registration-backend/models"
+	"event-registration-backend/requestid"
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
-	"google.golang.org/api/iterator"
+	gcfirestore "cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"golang.org/x/sync/singleflight"
 )
 
+// RegistrationChallenge, when set, must be solved by every registration.
+// main configures it from config.Config.
+var RegistrationChallenge challenge.Verifier
+
+type RegisterResponse struct {
+	Message string `json:"message"`
+}
+
+// CountResponse is the body of GetAttendeeCount and of each count event
+// on StreamAttendeeCount.
+type CountResponse struct {
+	Count int64 `json:"count"`
+}
+
 func RegisterAttendee(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
 
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apierror.Write(w, http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var req models.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, maxRegisterBytes) {
 		return
 	}
 
-	// Validate required fields
-	if req.FullName == "" || req.Email == "" || req.Designation == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+	// Bots that fill in the honeypot are told they succeeded, so they have
+	// no reason to try again, but nothing is stored.
+	if req.Website != "" {
+		log.Printf("request %s: honeypot registration ignored", requestid.FromContext(r.Context()))
+		writeRegistrationPending(w)
 		return
 	}
 
-	// Check if email already exists
-	ctx := context.Background()
-	attendeesRef := firestore.GetAttendeesCollection()
-	iter := attendeesRef.Where("email", "==", req.Email).Documents(ctx)
-	_, err := iter.Next()
-	if err == nil {
-		http.Error(w, "Email already registered", http.StatusConflict)
+	if err := validateRegisterRequest(&req); err != nil {
+		writeValidationError(w, "Invalid registration", err)
 		return
 	}
 
-	// Create new attendee
-	attendee := models.Attendee{
-		FullName:    req.FullName,
-		Email:       req.Email,
-		Designation: req.Designation,
-		CreatedAt:   time.Now(),
+	if RegistrationChallenge != nil {
+		err := RegistrationChallenge.Verify(r.Context(), r, req)
+		if errors.Is(err, challenge.ErrFailed) {
+			apierror.Write(w, http.StatusForbidden, apierror.CodeChallengeFailed, "Registration challenge not solved")
+			return
+		}
+		if err != nil {
+			apierror.Internal(w, r, "Failed to verify registration challenge", err)
+			return
+		}
 	}
 
-	_, _, err = attendeesRef.Add(ctx, attendee)
+	if !checkEmailDomain(w, r, req.Email) {
+		return
+	}
+
+	token, tokenHash, err := newVerificationToken()
 	if err != nil {
-		http.Error(w, "Failed to register attendee: "+err.Error(), http.StatusInternalServerError)
+		apierror.Internal(w, r, "Failed to create verification token", err)
+		return
+	}
+
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	attendeesRef := firestore.GetAttendeesCollection()
+	var (
+		attendee   models.Attendee
+		docRef     *gcfirestore.DocumentRef
+		resend     bool
+		retryAfter time.Duration
+	)
+	// The lookup, the capacity check and the write share a transaction, so
+	// concurrent registrations cannot take more seats than there are.
+	err = firestore.Client.RunTransaction(ctx, func(ctx context.Context, tx *gcfirestore.Transaction) error {
+		now := time.Now()
+		existing, err := findAttendeeByEmail(tx, req.Email)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.Status != models.StatusPendingVerification {
+			return errEmailTaken
+		}
+
+		// Registering again while pending only sends a fresh link. The
+		// details and the held seat stay as first registered.
+		if existing != nil && holdActive(existing, now) {
+			if retryAfter = resendWait(existing, now); retryAfter > 0 {
+				return errResendSoon
+			}
+			attendee = *existing
+			attendee.VerificationTokenHash = tokenHash
+			attendee.VerificationSentAt = &now
+			docRef, resend = attendeesRef.Doc(existing.ID), true
+			return tx.Update(docRef, []gcfirestore.Update{
+				{Path: "verificationTokenHash", Value: tokenHash},
+				{Path: "verificationSentAt", Value: now},
+			})
+		}
+
+		full, err := eventFull(ctx, tx, now)
+		if err != nil {
+			return err
+		}
+		if full {
+			return errEventFull
+		}
+		holdExpiresAt := now.Add(Registration.Hold)
+		attendee = models.Attendee{
+			FullName:              req.FullName,
+			Email:                 req.Email,
+			Designation:           req.Designation,
+			Status:                models.StatusPendingVerification,
+			CreatedAt:             now,
+			HoldExpiresAt:         &holdExpiresAt,
+			VerificationTokenHash: tokenHash,
+			VerificationSentAt:    &now,
+			SchemaVersion:         models.SchemaVersion,
+		}
+		resend = false
+		if existing != nil {
+			// The hold ended, so the seat was given up and is taken afresh
+			docRef = attendeesRef.Doc(existing.ID)
+			return tx.Set(docRef, attendee)
+		}
+		docRef = attendeesRef.NewDoc()
+		return tx.Create(docRef, attendee)
+	})
+	switch {
+	case errors.Is(err, errEmailTaken):
+		apierror.Write(w, http.StatusConflict, apierror.CodeEmailTaken, "Email already registered")
+		return
+	case errors.Is(err, errEventFull):
+		apierror.Write(w, http.StatusConflict, apierror.CodeEventFull, "The event is full")
+		return
+	case errors.Is(err, errResendSoon):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		apierror.Write(w, http.StatusTooManyRequests, apierror.CodeRateLimited, "A confirmation link was sent recently; check your email or try again later")
+		return
+	case err != nil:
+		writeStorageError(w, r, "Failed to register attendee", err)
 		return
 	}
+	attendee.ID = docRef.ID
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Registration successful"})
+	if err := Registration.Mailer.Send(ctx, verificationEmail(attendee, token)); err != nil {
+		// A new registration releases its seat so the attendee can simply try
+		// again, even if the send failed because the request ran out of
+		// time. A resend keeps the registration it was asked for.
+		if !resend {
+			releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), Timeouts.Request)
+			defer cancel()
+			if _, delErr := docRef.Delete(releaseCtx); delErr != nil {
+				log.Printf("request %s: failed to release seat of %s: %v", requestid.FromContext(r.Context()), attendee.ID, delErr)
+			}
+		}
+		apierror.Internal(w, r, "Failed to send verification email", err)
+		return
+	}
+
+	writeRegistrationPending(w)
 }
 
-func GetAttendeeCount(w http.ResponseWriter, r *http.Request) {
+// writeRegistrationPending tells the client to look for the verification
+// email.
+func writeRegistrationPending(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(RegisterResponse{Message: "Check your email to confirm your registration"})
+}
+
+// GetRegistrationChallenge describes the challenge RegisterAttendee requires,
+// so the form can solve it before submitting.
+func GetRegistrationChallenge(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	ctx := context.Background()
-	attendeesRef := firestore.GetAttendeesCollection()
+	c := challenge.Challenge{Type: challenge.TypeNone}
+	if RegistrationChallenge != nil {
+		c = RegistrationChallenge.Challenge()
+	}
+	json.NewEncoder(w).Encode(c)
+}
 
-	count := 0
-	iter := attendeesRef.Documents(ctx)
-	for {
-		_, err := iter.Next()
-		if err == iterator.Done {
-			break
-		}
+// attendeeCountTTL bounds how stale the public attendee count may be. Every
+// browser polls the count, so it is served from memory between refreshes.
+const attendeeCountTTL = 2 * time.Second
+
+// countCache memoizes the attendee count for attendeeCountTTL. Concurrent
+// misses share a single aggregation query. Like agendaCache, it counts
+// invalidations, so a load that started before the roster changed is
+// neither stored nor joined by callers that arrive after the change.
+type countCache struct {
+	mu         sync.Mutex
+	count      int64
+	expires    time.Time
+	generation uint64
+	group      singleflight.Group
+	ttl        time.Duration
+	load       func(ctx context.Context) (int64, error)
+}
+
+var attendeeCount = &countCache{ttl: attendeeCountTTL, load: countAttendees}
+
+func (c *countCache) get(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	if time.Now().Before(c.expires) {
+		count := c.count
+		c.mu.Unlock()
+		return count, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	v, err, _ := c.group.Do(strconv.FormatUint(generation, 10), func() (interface{}, error) {
+		// The load is shared, so one caller going away must not fail the rest
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), Timeouts.Request)
+		defer cancel()
+		count, err := c.load(loadCtx)
 		if err != nil {
-			http.Error(w, "Failed to count attendees: "+err.Error(), http.StatusInternalServerError)
-			return
+			return int64(0), err
 		}
-		count++
+		c.mu.Lock()
+		if c.generation == generation {
+			c.count = count
+			c.expires = time.Now().Add(c.ttl)
+		}
+		c.mu.Unlock()
+		return count, nil
+	})
+	return v.(int64), err
+}
+
+// refresh loads the count past the cache, for callers that know the roster
+// has just changed and must not be served a load that started before it.
+func (c *countCache) refresh(ctx context.Context) (int64, error) {
+	c.invalidate()
+	return c.get(ctx)
+}
+
+func (c *countCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expires = time.Time{}
+	c.generation++
+}
+
+// validateRegisterRequest holds the rules shared by RegisterAttendee and the
+// attendee bulk import. It normalizes req before checking it.
+func validateRegisterRequest(req *models.RegisterRequest) error {
+	req.Normalize()
+	return req.Validate()
+}
+
+// countAttendees counts confirmed registrations; pending ones do not hold a
+// confirmed seat yet.
+func countAttendees(ctx context.Context) (int64, error) {
+	return countQuery(ctx, nil, firestore.GetAttendeesCollection().Where("status", "==", models.StatusRegistered))
+}
+
+// countQuery asks Firestore for the number of documents matching q using
+// an aggregation query, so the cost does not grow with the collection size.
+// The count is read within tx when it is not nil.
+func countQuery(ctx context.Context, tx *gcfirestore.Transaction, q gcfirestore.Query) (int64, error) {
+	aq := q.NewAggregationQuery().WithCount("all")
+	if tx != nil {
+		aq = aq.Transaction(tx)
+	}
+	results, err := aq.Get(ctx)
+	if err != nil {
+		return 0, err
 	}
 
-	json.NewEncoder(w).Encode(map[string]int{"count": count})
+	value, ok := results["all"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count aggregation result: %v", results["all"])
+	}
+	return value.GetIntegerValue(), nil
 }
 
+func GetAttendeeCount(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	count, err := attendeeCount.get(ctx)
+	if err != nil {
+		writeStorageError(w, r, "Failed to count attendees", err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(CountResponse{Count: count})
+}
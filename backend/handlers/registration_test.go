@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/models"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountCache_ServesFromMemoryWithinTTL(t *testing.T) {
+	var loads int32
+	c := &countCache{ttl: time.Minute, load: func(ctx context.Context) (int64, error) {
+		return int64(atomic.AddInt32(&loads, 1)) * 10, nil
+	}}
+
+	for i := 0; i < 5; i++ {
+		count, err := c.get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), count)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+
+	c.invalidate()
+	count, err := c.get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), count)
+}
+
+func TestCountCache_ConcurrentMissesShareOneLoad(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	c := &countCache{ttl: time.Minute, load: func(ctx context.Context) (int64, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return 42, nil
+	}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count, err := c.get(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, int64(42), count)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
+
+func TestCountCache_ErrorsAreNotCached(t *testing.T) {
+	fail := true
+	c := &countCache{ttl: time.Minute, load: func(ctx context.Context) (int64, error) {
+		if fail {
+			return 0, errors.New("unavailable")
+		}
+		return 7, nil
+	}}
+
+	_, err := c.get(context.Background())
+	assert.Error(t, err)
+
+	fail = false
+	count, err := c.get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+}
+
+func TestCountCache_InvalidateDuringLoad(t *testing.T) {
+	var loads int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	c := &countCache{ttl: time.Minute, load: func(ctx context.Context) (int64, error) {
+		n := atomic.AddInt32(&loads, 1)
+		started <- struct{}{}
+		if n == 1 {
+			<-release
+		}
+		return int64(n), nil
+	}}
+
+	first := make(chan int64)
+	go func() {
+		count, _ := c.get(context.Background())
+		first <- count
+	}()
+	<-started
+
+	// A registration lands while the first load is reading the old count
+	c.invalidate()
+	count, err := c.get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count, "a get after invalidate must not join the stale load")
+
+	close(release)
+	assert.Equal(t, int64(1), <-first)
+
+	// The stale result was dropped; the fresh one stays cached
+	count, err = c.get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+}
+
+func TestCountCache_LoadOutlivesCallerCancellation(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c := &countCache{ttl: time.Minute, load: func(ctx context.Context) (int64, error) {
+		close(started)
+		<-release
+		return 9, ctx.Err()
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := c.get(ctx)
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan int64)
+	go func() {
+		count, err := c.get(context.Background())
+		assert.NoError(t, err)
+		second <- count
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The first caller disconnects while the load is shared
+	cancel()
+	close(release)
+
+	assert.NoError(t, <-firstErr)
+	assert.Equal(t, int64(9), <-second)
+}
+
+func TestCheckEmailDomain(t *testing.T) {
+	prev := emailDomainPolicy
+	t.Cleanup(func() { emailDomainPolicy = prev })
+	emailDomainPolicy = &policyCache{ttl: time.Minute, load: func(ctx context.Context) (models.EmailDomainPolicy, error) {
+		return models.EmailDomainPolicy{Blocked: []string{"spam.example"}}, nil
+	}}
+
+	tests := []struct {
+		email   string
+		allowed bool
+		message string
+	}{
+		{email: "ada@example.com", allowed: true},
+		{email: "ada@mailinator.com", message: "Disposable email addresses are not accepted; please use a permanent address"},
+		{email: "ada@spam.example", message: "Registrations from this email domain are not accepted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ok := checkEmailDomain(w, httptest.NewRequest("POST", "/api/v1/register", nil), tt.email)
+
+			assert.Equal(t, tt.allowed, ok)
+			if !tt.allowed {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				var envelope apierror.Envelope
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+				assert.Equal(t, map[string]string{"email": tt.message}, envelope.Fields)
+			}
+		})
+	}
+
+	// Saving a policy takes effect at once on this instance
+	emailDomainPolicy.set(models.EmailDomainPolicy{Allowed: []string{"acme.com"}, AllowedOnly: true})
+	w := httptest.NewRecorder()
+	assert.False(t, checkEmailDomain(w, httptest.NewRequest("POST", "/api/v1/register", nil), "ada@example.com"))
+	assert.Contains(t, w.Body.String(), "invited organizations")
+}
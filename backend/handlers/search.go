@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"event-registration-backend/apierror"
+	"event-registration-backend/events"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+	"event-registration-backend/search"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/api/iterator"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// searchRefreshInterval bounds how long registrations made through
+	// another server instance can be missing from this instance's index.
+	searchRefreshInterval = 5 * time.Minute
+)
+
+// attendeeSearch keeps an in-memory search index in sync with Firestore.
+// The index is loaded in full on first use and every searchRefreshInterval,
+// and kept current in between from roster events.
+type attendeeSearch struct {
+	index *search.Index
+	load  func(ctx context.Context) ([]models.Attendee, error)
+	// mu is held for a whole load, so concurrent searches share one
+	mu   sync.Mutex
+	once sync.Once
+
+	// eventsMu is only held briefly, so watch never waits for a load and
+	// keeps draining the roster. Roster events that arrive while a load
+	// runs are held in pending and replayed once the loaded attendees are
+	// in the index; applying them straight away would let Replace discard
+	// them.
+	eventsMu sync.Mutex
+	loadedAt time.Time
+	loading  bool
+	pending  []events.Event
+}
+
+var attendeeIndex = &attendeeSearch{index: search.NewIndex(), load: fetchAllAttendees}
+
+func (s *attendeeSearch) ensureLoaded(ctx context.Context) error {
+	// Subscribe before the first load so registrations that land while it
+	// runs are not lost.
+	s.once.Do(func() {
+		roster, _ := eventHub.Subscribe(256)
+		go s.watch(roster)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventsMu.Lock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < searchRefreshInterval {
+		s.eventsMu.Unlock()
+		return nil
+	}
+	s.loading = true
+	s.eventsMu.Unlock()
+
+	attendees, err := s.load(ctx)
+
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+	if err == nil {
+		s.index.Replace(attendees)
+		s.loadedAt = time.Now()
+	}
+	for _, e := range s.pending {
+		s.apply(e)
+	}
+	s.pending, s.loading = nil, false
+	return err
+}
+
+func (s *attendeeSearch) watch(roster <-chan events.Event) {
+	for e := range roster {
+		s.eventsMu.Lock()
+		if s.loading {
+			s.pending = append(s.pending, e)
+		} else {
+			s.apply(e)
+		}
+		s.eventsMu.Unlock()
+	}
+}
+
+// apply updates the index for a single roster event. An import changes too
+// much to follow, so the next search reloads instead. The caller holds
+// eventsMu.
+func (s *attendeeSearch) apply(e events.Event) {
+	if e.Type == events.TypeImport {
+		s.loadedAt = time.Time{}
+		return
+	}
+	attendee, ok := e.Data.(models.Attendee)
+	if !ok {
+		return
+	}
+	switch e.Type {
+	case events.TypeRegistration, events.TypeCheckIn:
+		s.index.Add(attendee)
+	case events.TypeCancellation, events.TypeExpiry:
+		s.index.Remove(attendee.ID)
+	}
+}
+
+// fetchAllAttendees reads the whole attendees collection.
+func fetchAllAttendees(ctx context.Context) ([]models.Attendee, error) {
+	var attendees []models.Attendee
+	iter := firestore.GetAttendeesCollection().Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var attendee models.Attendee
+		if err := doc.DataTo(&attendee); err != nil {
+			continue
+		}
+		attendee.ID = doc.Ref.ID
+		attendees = append(attendees, attendee)
+	}
+	return attendees, nil
+}
+
+// SearchAttendees finds attendees whose name, email or designation match
+// the q parameter by prefix or with small typos, best matches first.
+func SearchAttendees(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "Query parameter q is required")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			apierror.Write(w, http.StatusBadRequest, apierror.CodeInvalidRequest, "limit must be between 1 and "+strconv.Itoa(maxSearchLimit))
+			return
+		}
+		limit = n
+	}
+
+	ctx, cancel := bulkStorageContext(w, r)
+	defer cancel()
+	if err := attendeeIndex.ensureLoaded(ctx); err != nil {
+		writeStorageError(w, r, "Failed to load attendees", err)
+		return
+	}
+
+	results := attendeeIndex.index.Search(query, limit)
+	if results == nil {
+		results = []models.Attendee{}
+	}
+	json.NewEncoder(w).Encode(models.AttendeePage{Attendees: results})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"event-registration-backend/events"
+	"event-registration-backend/models"
+	"event-registration-backend/search"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubAttendeeIndex(t *testing.T, attendees []models.Attendee) {
+	t.Helper()
+	original := attendeeIndex
+	attendeeIndex = &attendeeSearch{
+		index: search.NewIndex(),
+		load: func(ctx context.Context) ([]models.Attendee, error) {
+			return attendees, nil
+		},
+	}
+	t.Cleanup(func() { attendeeIndex = original })
+}
+
+func searchAttendees(t *testing.T, query string) (int, []models.Attendee) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	SearchAttendees(w, httptest.NewRequest("GET", "/api/admin/attendees/search?"+query, nil))
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+	var page models.AttendeePage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	return w.Code, page.Attendees
+}
+
+func TestSearchAttendees(t *testing.T) {
+	stubAttendeeIndex(t, []models.Attendee{
+		{ID: "1", FullName: "Jane Doe", Email: "jane@acme.com", Designation: "Designer"},
+	})
+
+	status, results := searchAttendees(t, "q=jan")
+	assert.Equal(t, http.StatusOK, status)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Jane Doe", results[0].FullName)
+
+	// New registrations become searchable without a reload.
+	eventHub.Publish(events.Event{
+		Type: events.TypeRegistration,
+		Data: models.Attendee{ID: "2", FullName: "Janet Smith", Email: "janet@example.com"},
+	})
+	assert.Eventually(t, func() bool {
+		_, results := searchAttendees(t, "q=smith")
+		return len(results) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Expired pending registrations drop out.
+	eventHub.Publish(events.Event{Type: events.TypeExpiry, Data: models.Attendee{ID: "1"}})
+	assert.Eventually(t, func() bool {
+		_, results := searchAttendees(t, "q=jan")
+		return len(results) == 1 && results[0].ID == "2"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSearchAttendees_KeepsEventsFromDuringLoad(t *testing.T) {
+	original := attendeeIndex
+	t.Cleanup(func() { attendeeIndex = original })
+	idx := &attendeeSearch{index: search.NewIndex()}
+	idx.load = func(ctx context.Context) ([]models.Attendee, error) {
+		// The read has already passed this registration by
+		eventHub.Publish(events.Event{
+			Type: events.TypeRegistration,
+			Data: models.Attendee{ID: "2", FullName: "Janet Smith", Email: "janet@example.com"},
+		})
+		require.Eventually(t, func() bool {
+			idx.eventsMu.Lock()
+			defer idx.eventsMu.Unlock()
+			return len(idx.pending) == 1
+		}, time.Second, time.Millisecond)
+		return []models.Attendee{{ID: "1", FullName: "Jane Doe", Email: "jane@acme.com"}}, nil
+	}
+	attendeeIndex = idx
+
+	_, results := searchAttendees(t, "q=doe")
+	assert.Len(t, results, 1)
+	_, results = searchAttendees(t, "q=smith")
+	assert.Len(t, results, 1, "a registration made during the load must survive it")
+}
+
+func TestSearchAttendees_BadRequest(t *testing.T) {
+	stubAttendeeIndex(t, nil)
+
+	for _, query := range []string{"", "q=", "q=jane&limit=0", "q=jane&limit=abc"} {
+		status, _ := searchAttendees(t, query)
+		assert.Equal(t, http.StatusBadRequest, status, query)
+	}
+}
+
+func TestSearchAttendees_KeepsWatchingDuringLoad(t *testing.T) {
+	original := attendeeIndex
+	t.Cleanup(func() { attendeeIndex = original })
+	idx := &attendeeSearch{index: search.NewIndex()}
+	idx.load = func(ctx context.Context) ([]models.Attendee, error) {
+		// An import must not stall the watcher behind this load, or the
+		// registration after it would be dropped
+		eventHub.Publish(events.Event{Type: events.TypeImport, Data: map[string]interface{}{"kind": "attendees"}})
+		eventHub.Publish(events.Event{
+			Type: events.TypeRegistration,
+			Data: models.Attendee{ID: "2", FullName: "Janet Smith", Email: "janet@example.com"},
+		})
+		require.Eventually(t, func() bool {
+			idx.eventsMu.Lock()
+			defer idx.eventsMu.Unlock()
+			return len(idx.pending) == 2
+		}, time.Second, time.Millisecond)
+		return []models.Attendee{{ID: "1", FullName: "Jane Doe", Email: "jane@acme.com"}}, nil
+	}
+	attendeeIndex = idx
+
+	require.NoError(t, idx.ensureLoaded(context.Background()))
+	assert.Equal(t, 2, idx.index.Len())
+	idx.eventsMu.Lock()
+	defer idx.eventsMu.Unlock()
+	assert.True(t, idx.loadedAt.IsZero(), "an import during the load leaves the index to be reloaded")
+}
+
+func TestSearchAttendees_NoTermsFindsNothing(t *testing.T) {
+	stubAttendeeIndex(t, []models.Attendee{{ID: "1", FullName: "Jane Doe", Email: "jane@acme.com"}})
+
+	w := httptest.NewRecorder()
+	SearchAttendees(w, httptest.NewRequest("GET", "/api/admin/attendees/search?q=...", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"attendees":[]}`, w.Body.String())
+}
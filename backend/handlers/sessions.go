@@ -6,63 +6,170 @@ import (
 	"event-registration-backend/firestore"
 	"event-registration-backend/models"
 	"net/http"
+	"sync"
+	"time"
+
+	gcfirestore "cloud.google.com/go/firestore"
 )
 
+// agendaCacheTTL bounds how long edits made outside this process, such as
+// eventctl seed, take to show up in GetSessions.
+const agendaCacheTTL = time.Minute
+
+// agendaCache holds the joined sessions/speakers list served by GetSessions.
+// It is invalidated whenever an admin adds or updates a speaker or session.
+type agendaCache struct {
+	mu         sync.RWMutex
+	sessions   []models.SessionWithSpeaker
+	valid      bool
+	loadedAt   time.Time
+	generation uint64
+}
+
+var agenda = &agendaCache{}
+
+// get returns the cached agenda, if any, and the generation it was read at.
+func (c *agendaCache) get() ([]models.SessionWithSpeaker, bool, uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	valid := c.valid && time.Since(c.loadedAt) < agendaCacheTTL
+	return c.sessions, valid, c.generation
+}
+
+// set stores a freshly loaded agenda unless the cache was invalidated
+// after the load started, in which case the result may already be stale.
+func (c *agendaCache) set(sessions []models.SessionWithSpeaker, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return
+	}
+	c.sessions = sessions
+	c.valid = true
+	c.loadedAt = time.Now()
+}
+
+func (c *agendaCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions = nil
+	c.valid = false
+	c.generation++
+}
+
+// InvalidateAgenda drops the cached agenda so the next GetSessions call
+// reloads it from Firestore.
+func InvalidateAgenda() {
+	agenda.invalidate()
+}
+
 func GetSessions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	ctx := context.Background()
-	sessionsRef := firestore.GetSessionsCollection()
-	speakersRef := firestore.GetSpeakersCollection()
+	sessionsWithSpeakers, ok, generation := agenda.get()
+	if !ok {
+		ctx, cancel := storageContext(r)
+		defer cancel()
+		var err error
+		sessionsWithSpeakers, err = loadAgenda(ctx)
+		if err != nil {
+			writeStorageError(w, r, "Failed to fetch sessions", err)
+			return
+		}
+		agenda.set(sessionsWithSpeakers, generation)
+	}
 
-	sessionsSnapshot, err := sessionsRef.Documents(ctx).GetAll()
+	json.NewEncoder(w).Encode(sessionsWithSpeakers)
+}
+
+// loadAgenda reads all sessions and joins their speakers using a single
+// batched GetAll instead of one Get per session.
+func loadAgenda(ctx context.Context) ([]models.SessionWithSpeaker, error) {
+	sessionsSnapshot, err := firestore.GetSessionsCollection().Documents(ctx).GetAll()
 	if err != nil {
-		http.Error(w, "Failed to fetch sessions: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
-	var sessionsWithSpeakers []models.SessionWithSpeaker
-
+	var sessions []models.Session
 	for _, doc := range sessionsSnapshot {
 		var session models.Session
 		if err := doc.DataTo(&session); err != nil {
 			continue
 		}
 		session.ID = doc.Ref.ID
+		sessions = append(sessions, session)
+	}
 
-		sessionWithSpeaker := models.SessionWithSpeaker{
-			Session: session,
-		}
+	speakers, err := fetchSpeakers(ctx, sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	return joinSpeakers(sessions, speakers), nil
+}
 
-		// Fetch speaker details
-		if session.SpeakerID != "" {
-			speakerDoc, err := speakersRef.Doc(session.SpeakerID).Get(ctx)
-			if err == nil {
-				var speaker models.Speaker
-				if err := speakerDoc.DataTo(&speaker); err == nil {
-					speaker.ID = speakerDoc.Ref.ID
-					sessionWithSpeaker.Speaker = &speaker
-				}
-			}
+// fetchSpeakers loads every distinct speaker referenced by sessions in one
+// round-trip. Missing or malformed speaker documents are left out.
+func fetchSpeakers(ctx context.Context, sessions []models.Session) (map[string]*models.Speaker, error) {
+	speakersRef := firestore.GetSpeakersCollection()
+
+	seen := make(map[string]bool)
+	var refs []*gcfirestore.DocumentRef
+	for _, session := range sessions {
+		if session.SpeakerID == "" || seen[session.SpeakerID] {
+			continue
 		}
+		seen[session.SpeakerID] = true
+		refs = append(refs, speakersRef.Doc(session.SpeakerID))
+	}
 
-		sessionsWithSpeakers = append(sessionsWithSpeakers, sessionWithSpeaker)
+	speakers := make(map[string]*models.Speaker, len(refs))
+	if len(refs) == 0 {
+		return speakers, nil
 	}
 
-	json.NewEncoder(w).Encode(sessionsWithSpeakers)
+	docs, err := firestore.Client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		var speaker models.Speaker
+		if err := doc.DataTo(&speaker); err != nil {
+			continue
+		}
+		speaker.ID = doc.Ref.ID
+		speakers[speaker.ID] = &speaker
+	}
+
+	return speakers, nil
+}
+
+// joinSpeakers attaches each session's speaker, preserving session order.
+func joinSpeakers(sessions []models.Session, speakers map[string]*models.Speaker) []models.SessionWithSpeaker {
+	var sessionsWithSpeakers []models.SessionWithSpeaker
+	for _, session := range sessions {
+		sessionsWithSpeakers = append(sessionsWithSpeakers, models.SessionWithSpeaker{
+			Session: session,
+			Speaker: speakers[session.SpeakerID],
+		})
+	}
+	return sessionsWithSpeakers
 }
 
 func GetSpeakers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	ctx := context.Background()
+	ctx, cancel := storageContext(r)
+	defer cancel()
 	speakersRef := firestore.GetSpeakersCollection()
 
 	speakersSnapshot, err := speakersRef.Documents(ctx).GetAll()
 	if err != nil {
-		http.Error(w, "Failed to fetch speakers: "+err.Error(), http.StatusInternalServerError)
+		writeStorageError(w, r, "Failed to fetch speakers", err)
 		return
 	}
 
@@ -78,4 +185,3 @@ func GetSpeakers(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(speakers)
 }
-
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"event-registration-backend/firestore"
+	"event-registration-backend/models"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	gcfirestore "cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const benchSessionCount = 30
+
+func TestJoinSpeakers(t *testing.T) {
+	sessions := []models.Session{
+		{ID: "s1", Title: "Keynote", SpeakerID: "sp1"},
+		{ID: "s2", Title: "Break"},
+		{ID: "s3", Title: "Panel", SpeakerID: "missing"},
+		{ID: "s4", Title: "Closing", SpeakerID: "sp1"},
+	}
+	speakers := map[string]*models.Speaker{
+		"sp1": {ID: "sp1", Name: "Jane Doe"},
+	}
+
+	joined := joinSpeakers(sessions, speakers)
+
+	require.Len(t, joined, 4)
+	assert.Equal(t, "s1", joined[0].ID)
+	assert.Equal(t, "Jane Doe", joined[0].Speaker.Name)
+	assert.Nil(t, joined[1].Speaker)
+	assert.Nil(t, joined[2].Speaker)
+	assert.Same(t, joined[0].Speaker, joined[3].Speaker)
+}
+
+func TestAgendaCache(t *testing.T) {
+	c := &agendaCache{}
+
+	_, ok, generation := c.get()
+	assert.False(t, ok)
+
+	c.set([]models.SessionWithSpeaker{{Session: models.Session{ID: "s1"}}}, generation)
+	sessions, ok, _ := c.get()
+	assert.True(t, ok)
+	assert.Len(t, sessions, 1)
+
+	c.invalidate()
+	_, ok, _ = c.get()
+	assert.False(t, ok)
+}
+
+func TestAgendaCache_StaleLoadDiscarded(t *testing.T) {
+	c := &agendaCache{}
+
+	_, _, generation := c.get()
+	// An admin write lands while the agenda is being loaded.
+	c.invalidate()
+	c.set([]models.SessionWithSpeaker{{Session: models.Session{ID: "stale"}}}, generation)
+
+	_, ok, _ := c.get()
+	assert.False(t, ok, "a load that raced with an invalidation must not be cached")
+}
+
+// setupEmulator points the firestore package at the Firestore emulator and
+// seeds it with benchSessionCount sessions, each with its own speaker.
+// Benchmarks that need real round-trips are skipped without an emulator.
+func setupEmulator(b *testing.B) {
+	b.Helper()
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		b.Skip("FIRESTORE_EMULATOR_HOST not set, skipping Firestore benchmark")
+	}
+
+	ctx := context.Background()
+	client, err := gcfirestore.NewClient(ctx, "tcmp-bench")
+	require.NoError(b, err)
+	b.Cleanup(func() { client.Close() })
+
+	firestore.Client = client
+	firestore.ClientID = "bench"
+
+	for i := 0; i < benchSessionCount; i++ {
+		speakerID := fmt.Sprintf("speaker-%02d", i)
+		_, err := firestore.GetSpeakersCollection().Doc(speakerID).Set(ctx, models.Speaker{
+			Name: fmt.Sprintf("Speaker %d", i),
+		})
+		require.NoError(b, err)
+		_, err = firestore.GetSessionsCollection().Doc(fmt.Sprintf("session-%02d", i)).Set(ctx, models.Session{
+			Title:     fmt.Sprintf("Session %d", i),
+			SpeakerID: speakerID,
+		})
+		require.NoError(b, err)
+	}
+}
+
+// loadAgendaPerSession is the previous GetSessions strategy, kept here as a
+// baseline: one Get per session to fetch its speaker.
+func loadAgendaPerSession(ctx context.Context) ([]models.SessionWithSpeaker, error) {
+	docs, err := firestore.GetSessionsCollection().Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var sessionsWithSpeakers []models.SessionWithSpeaker
+	for _, doc := range docs {
+		var session models.Session
+		if err := doc.DataTo(&session); err != nil {
+			continue
+		}
+		session.ID = doc.Ref.ID
+		sessionWithSpeaker := models.SessionWithSpeaker{Session: session}
+		if session.SpeakerID != "" {
+			speakerDoc, err := firestore.GetSpeakersCollection().Doc(session.SpeakerID).Get(ctx)
+			if err == nil {
+				var speaker models.Speaker
+				if err := speakerDoc.DataTo(&speaker); err == nil {
+					speaker.ID = speakerDoc.Ref.ID
+					sessionWithSpeaker.Speaker = &speaker
+				}
+			}
+		}
+		sessionsWithSpeakers = append(sessionsWithSpeakers, sessionWithSpeaker)
+	}
+	return sessionsWithSpeakers, nil
+}
+
+func BenchmarkLoadAgenda_PerSessionGet(b *testing.B) {
+	setupEmulator(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := loadAgendaPerSession(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadAgenda_Batched(b *testing.B) {
+	setupEmulator(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := loadAgenda(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetSessions_Cached(b *testing.B) {
+	sessions := make([]models.Session, benchSessionCount)
+	speakers := make(map[string]*models.Speaker, benchSessionCount)
+	for i := range sessions {
+		id := fmt.Sprintf("speaker-%02d", i)
+		sessions[i] = models.Session{ID: fmt.Sprintf("session-%02d", i), Title: "Session", SpeakerID: id}
+		speakers[id] = &models.Speaker{ID: id, Name: "Speaker"}
+	}
+	_, _, generation := agenda.get()
+	agenda.set(joinSpeakers(sessions, speakers), generation)
+	b.Cleanup(InvalidateAgenda)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		GetSessions(w, httptest.NewRequest("GET", "/api/sessions", nil))
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/requestid"
+	"log"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// storageRetryAfter is suggested to clients when storage is unavailable.
+const storageRetryAfter = "5"
+
+// StorageTimeouts bound the storage calls made while serving a request.
+type StorageTimeouts struct {
+	// Request bounds ordinary reads and writes.
+	Request time.Duration
+	// Bulk bounds whole-collection work: exports, imports, backups and
+	// loading the search index.
+	Bulk time.Duration
+}
+
+// Timeouts is set by main from config.Config.
+var Timeouts = StorageTimeouts{
+	Request: 10 * time.Second,
+	Bulk:    2 * time.Minute,
+}
+
+// storageContext returns r's context with the request storage deadline,
+// so a client that disconnects or a slow backend cancels the query.
+func storageContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), Timeouts.Request)
+}
+
+// bulkStorageContext is storageContext for whole-collection work. It also
+// extends the response's write deadline past the server's write timeout,
+// which would otherwise cut off the result.
+func bulkStorageContext(w http.ResponseWriter, r *http.Request) (context.Context, context.CancelFunc) {
+	extendWriteDeadline(w)
+	return context.WithTimeout(r.Context(), Timeouts.Bulk)
+}
+
+// bulkWriteContext is bulkStorageContext for multi-step writes such as
+// imports and restores. They are not cancelled when the client disconnects,
+// which would leave them half done.
+func bulkWriteContext(w http.ResponseWriter, r *http.Request) (context.Context, context.CancelFunc) {
+	extendWriteDeadline(w)
+	return context.WithTimeout(context.WithoutCancel(r.Context()), Timeouts.Bulk)
+}
+
+func extendWriteDeadline(w http.ResponseWriter) {
+	// Not every ResponseWriter supports deadlines; tests use a recorder
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(Timeouts.Bulk + streamWriteWait))
+}
+
+// writeStorageError replies to a failed storage call: 504 when it ran out
+// of time, 503 when storage is unavailable, overloaded or too contended
+// for a transaction to commit, and 500 otherwise. Nothing is written when
+// the client has already gone away.
+func writeStorageError(w http.ResponseWriter, r *http.Request, what string, err error) {
+	if r.Context().Err() != nil {
+		log.Printf("request %s: %s %s: %s: client went away: %v", requestid.FromContext(r.Context()), r.Method, r.URL.Path, what, err)
+		return
+	}
+
+	switch code := status.Code(err); {
+	case errors.Is(err, context.DeadlineExceeded) || code == codes.DeadlineExceeded:
+		log.Printf("request %s: %s %s: %s: %v", requestid.FromContext(r.Context()), r.Method, r.URL.Path, what, err)
+		apierror.Write(w, http.StatusGatewayTimeout, apierror.CodeTimeout, "The request took too long; please try again")
+	case code == codes.Unavailable || code == codes.ResourceExhausted || code == codes.Aborted:
+		log.Printf("request %s: %s %s: %s: %v", requestid.FromContext(r.Context()), r.Method, r.URL.Path, what, err)
+		w.Header().Set("Retry-After", storageRetryAfter)
+		apierror.Write(w, http.StatusServiceUnavailable, apierror.CodeUnavailable, "The service is temporarily unavailable; please try again shortly")
+	default:
+		apierror.Internal(w, r, what, err)
+	}
+}
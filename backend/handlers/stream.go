@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"event-registration-backend/apierror"
+	"event-registration-backend/events"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// heartbeatInterval keeps idle SSE connections from being closed by
+// proxies and load balancers.
+const heartbeatInterval = 15 * time.Second
+
+// streamWriteWait bounds each write to a live stream. The server's own
+// read and write timeouts would end streams that outlive them, so streams
+// replace them with a deadline per write.
+const streamWriteWait = 10 * time.Second
+
+// eventHub carries roster and agenda changes from the handlers that make
+// them to every live feed.
+var eventHub = events.NewHub()
+
+// closeSignal is closed once, telling long-lived handlers to return.
+type closeSignal struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func newCloseSignal() *closeSignal {
+	return &closeSignal{ch: make(chan struct{})}
+}
+
+func (c *closeSignal) done() <-chan struct{} { return c.ch }
+
+func (c *closeSignal) close() { c.once.Do(func() { close(c.ch) }) }
+
+// streams ends SSE and WebSocket feeds when the server shuts down.
+var streams = newCloseSignal()
+
+// CloseStreams ends every live feed. http.Server.Shutdown waits for
+// in-flight requests but not for hijacked WebSockets, and streams never
+// finish on their own, so main registers this with RegisterOnShutdown.
+func CloseStreams() {
+	streams.close()
+}
+
+// countFeed turns roster changes into attendee count updates. A single
+// goroutine reloads the count per burst of changes and fans the result out,
+// so the number of viewers does not affect how often Firestore is queried.
+type countFeed struct {
+	hub     *events.Hub
+	changed chan struct{}
+	once    sync.Once
+}
+
+var attendeeCountFeed = &countFeed{
+	hub:     events.NewHub(),
+	changed: make(chan struct{}, 1),
+}
+
+func (f *countFeed) start() {
+	f.once.Do(func() {
+		roster, _ := eventHub.Subscribe(16)
+		go f.watch(roster)
+		go f.run()
+	})
+}
+
+// watch marks the count dirty whenever the roster changes.
+func (f *countFeed) watch(roster <-chan events.Event) {
+	for e := range roster {
+		switch e.Type {
+		case events.TypeRegistration, events.TypeCancellation, events.TypeImport:
+		default:
+			continue
+		}
+		select {
+		case f.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (f *countFeed) run() {
+	for range f.changed {
+		// The event means the cached count, or a load already in flight, may
+		// predate the change
+		count, err := attendeeCount.refresh(context.Background())
+		if err != nil {
+			log.Printf("Failed to refresh attendee count: %v", err)
+			continue
+		}
+		f.hub.Publish(events.Event{Type: events.TypeCount, Data: count})
+	}
+}
+
+// StreamAttendeeCount pushes the attendee count to the client as
+// Server-Sent Events: once on connect and again after every registration.
+func StreamAttendeeCount(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		apierror.Internal(w, r, "Streaming unsupported", fmt.Errorf("%T does not implement http.Flusher", w))
+		return
+	}
+
+	attendeeCountFeed.start()
+	updates, unsubscribe := attendeeCountFeed.hub.Subscribe(1)
+	defer unsubscribe()
+
+	ctx, cancel := storageContext(r)
+	count, err := attendeeCount.get(ctx)
+	cancel()
+	if err != nil {
+		writeStorageError(w, r, "Failed to count attendees", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	// Not every ResponseWriter supports deadlines; tests use a recorder
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	send := func(write func()) {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteWait))
+		write()
+		flusher.Flush()
+	}
+
+	send(func() { writeCountEvent(w, count) })
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-streams.done():
+			return
+		case <-heartbeat.C:
+			send(func() { fmt.Fprint(w, ": heartbeat\n\n") })
+		case e := <-updates:
+			count, ok := e.Data.(int64)
+			if !ok {
+				continue
+			}
+			send(func() { writeCountEvent(w, count) })
+		}
+	}
+}
+
+func writeCountEvent(w http.ResponseWriter, count int64) {
+	data, _ := json.Marshal(CountResponse{Count: count})
+	fmt.Fprintf(w, "event: count\ndata: %s\n\n", data)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"event-registration-backend/events"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAttendeeCount replaces the Firestore-backed count with a counter the
+// test controls.
+func stubAttendeeCount(t *testing.T, count *int64) {
+	t.Helper()
+	original := attendeeCount.load
+	attendeeCount.load = func(ctx context.Context) (int64, error) {
+		return atomic.LoadInt64(count), nil
+	}
+	attendeeCount.invalidate()
+	t.Cleanup(func() {
+		attendeeCount.load = original
+		attendeeCount.invalidate()
+	})
+}
+
+// readEvent returns the next "data:" line from an SSE stream, skipping
+// heartbeats and event names.
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "data: ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+func TestStreamAttendeeCount(t *testing.T) {
+	count := int64(3)
+	stubAttendeeCount(t, &count)
+
+	server := httptest.NewServer(http.HandlerFunc(StreamAttendeeCount))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, `{"count":3}`, readEvent(t, reader))
+
+	atomic.StoreInt64(&count, 4)
+	attendeeCount.invalidate()
+	eventHub.Publish(events.Event{Type: events.TypeRegistration})
+
+	assert.Equal(t, `{"count":4}`, readEvent(t, reader))
+}
+
+func TestStreamAttendeeCount_IgnoresLoadsStartedBeforeEvent(t *testing.T) {
+	count := int64(3)
+	var gated int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	original := attendeeCount.load
+	attendeeCount.load = func(ctx context.Context) (int64, error) {
+		n := atomic.LoadInt64(&count)
+		if atomic.CompareAndSwapInt32(&gated, 1, 0) {
+			close(started)
+			<-release
+		}
+		return n, nil
+	}
+	attendeeCount.invalidate()
+	t.Cleanup(func() {
+		attendeeCount.load = original
+		attendeeCount.invalidate()
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(StreamAttendeeCount))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, `{"count":3}`, readEvent(t, reader))
+
+	// A poll starts loading the count of 3 and stalls
+	atomic.StoreInt32(&gated, 1)
+	attendeeCount.invalidate()
+	go attendeeCount.get(context.Background())
+	<-started
+
+	// Meanwhile a registration is confirmed; the publisher does not even
+	// need to invalidate for the feed to reload
+	atomic.StoreInt64(&count, 4)
+	eventHub.Publish(events.Event{Type: events.TypeRegistration})
+	assert.Equal(t, `{"count":4}`, readEvent(t, reader))
+
+	// The stalled load finishes with the old count, which is not cached
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+	got, err := attendeeCount.get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), got)
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"event-registration-backend/apierror"
+	"event-registration-backend/events"
+	"event-registration-backend/firestore"
+	"event-registration-backend/mailer"
+	"event-registration-backend/models"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	gcfirestore "cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// verificationCleanupInterval is how often expired pending registrations
+// are deleted.
+const verificationCleanupInterval = 10 * time.Minute
+
+// verificationResendInterval is how long a pending registration waits
+// between verification emails, so registering again cannot flood an inbox.
+const verificationResendInterval = 10 * time.Minute
+
+// maxVerifyBytes limits the body of VerifyRegistration.
+const maxVerifyBytes = 1 << 10
+
+// RegistrationSettings configures email verification of public
+// registrations.
+type RegistrationSettings struct {
+	Mailer mailer.Sender
+	// PublicURL is the site's base URL, used in verification links.
+	PublicURL string
+	// Hold is how long a pending registration keeps its seat.
+	Hold time.Duration
+	// Capacity caps confirmed registrations plus held seats; zero means
+	// unlimited.
+	Capacity int
+}
+
+// Registration is set by main from config.Config.
+var Registration = RegistrationSettings{
+	Mailer:    mailer.Log{},
+	PublicURL: "http://localhost:8080",
+	Hold:      24 * time.Hour,
+}
+
+// VerifyRequest is the body of VerifyRegistration.
+type VerifyRequest struct {
+	Token string `json:"token"`
+}
+
+var (
+	errHoldExpired = errors.New("verification hold expired")
+	errEmailTaken  = errors.New("email already registered")
+	errEventFull   = errors.New("event is full")
+	errResendSoon  = errors.New("verification link sent recently")
+)
+
+// newVerificationToken returns a random token for the verification link and
+// the hash stored in its place.
+func newVerificationToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashVerificationToken(token), nil
+}
+
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// verificationEmail is the message carrying attendee's verification link.
+func verificationEmail(attendee models.Attendee, token string) mailer.Message {
+	link := Registration.PublicURL + "/verify?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      attendee.Email,
+		Subject: "Confirm your registration",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Thanks for registering. Confirm your email address to secure your seat:\n\n"+
+			"%s\n\n"+
+			"Your seat is held until %s. If you did not register, ignore this email and the registration will be removed.\n",
+			attendee.FullName, link, attendee.HoldExpiresAt.UTC().Format("Mon 2 Jan 2006 15:04 MST")),
+	}
+}
+
+// holdActive reports whether a pending registration still holds its seat.
+func holdActive(attendee *models.Attendee, now time.Time) bool {
+	return attendee.HoldExpiresAt != nil && now.Before(*attendee.HoldExpiresAt)
+}
+
+// resendWait returns how long a pending registration must wait before it
+// is sent another verification link, or zero if one can be sent now.
+func resendWait(attendee *models.Attendee, now time.Time) time.Duration {
+	sentAt := attendee.CreatedAt
+	if attendee.VerificationSentAt != nil {
+		sentAt = *attendee.VerificationSentAt
+	}
+	return max(sentAt.Add(verificationResendInterval).Sub(now), 0)
+}
+
+// findAttendeeByEmail returns the attendee registered with email, or nil,
+// reading it within tx.
+func findAttendeeByEmail(tx *gcfirestore.Transaction, email string) (*models.Attendee, error) {
+	iter := tx.Documents(firestore.GetAttendeesCollection().Where("email", "==", email).Limit(1))
+	defer iter.Stop()
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var attendee models.Attendee
+	if err := doc.DataTo(&attendee); err != nil {
+		return nil, err
+	}
+	attendee.ID = doc.Ref.ID
+	return &attendee, nil
+}
+
+// eventFull reports whether confirmed registrations and unexpired holds
+// have taken every seat. It counts within tx, so a registration that takes
+// a seat in the same transaction cannot overshoot the capacity.
+func eventFull(ctx context.Context, tx *gcfirestore.Transaction, now time.Time) (bool, error) {
+	if Registration.Capacity <= 0 {
+		return false, nil
+	}
+	attendeesRef := firestore.GetAttendeesCollection()
+	confirmed, err := countQuery(ctx, tx, attendeesRef.Where("status", "==", models.StatusRegistered))
+	if err != nil {
+		return false, err
+	}
+	// Only pending registrations have a hold, so this needs no composite index
+	held, err := countQuery(ctx, tx, attendeesRef.Where("holdExpiresAt", ">", now))
+	if err != nil {
+		return false, err
+	}
+	return confirmed+held >= int64(Registration.Capacity), nil
+}
+
+// VerifyRegistration confirms the registration whose emailed token is sent,
+// turning its held seat into a confirmed one. Confirming twice succeeds.
+func VerifyRegistration(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req VerifyRequest
+	if !decodeJSON(w, r, &req, maxVerifyBytes) {
+		return
+	}
+	if req.Token == "" || len(req.Token) > 128 {
+		apierror.WriteFields(w, http.StatusBadRequest, apierror.CodeValidationFailed, "Invalid confirmation",
+			map[string]string{"token": "Token is required"})
+		return
+	}
+
+	ctx, cancel := storageContext(r)
+	defer cancel()
+	iter := firestore.GetAttendeesCollection().Where("verificationTokenHash", "==", hashVerificationToken(req.Token)).Limit(1).Documents(ctx)
+	doc, err := iter.Next()
+	iter.Stop()
+	if err == iterator.Done {
+		apierror.Write(w, http.StatusNotFound, apierror.CodeNotFound, "This confirmation link is not valid")
+		return
+	}
+	if err != nil {
+		writeStorageError(w, r, "Failed to look up registration", err)
+		return
+	}
+
+	var attendee models.Attendee
+	confirmed := false
+	err = firestore.Client.RunTransaction(ctx, func(ctx context.Context, tx *gcfirestore.Transaction) error {
+		snap, err := tx.Get(doc.Ref)
+		if err != nil {
+			return err
+		}
+		attendee = models.Attendee{}
+		if err := snap.DataTo(&attendee); err != nil {
+			return err
+		}
+		attendee.ID = snap.Ref.ID
+		if attendee.Status != models.StatusPendingVerification {
+			return nil
+		}
+		now := time.Now()
+		if !holdActive(&attendee, now) {
+			return errHoldExpired
+		}
+		confirmed = true
+		attendee.Status = models.StatusRegistered
+		attendee.VerifiedAt = &now
+		attendee.HoldExpiresAt = nil
+		return tx.Update(snap.Ref, []gcfirestore.Update{
+			{Path: "status", Value: models.StatusRegistered},
+			{Path: "verifiedAt", Value: now},
+			{Path: "holdExpiresAt", Value: gcfirestore.Delete},
+		})
+	})
+	if status.Code(err) == codes.NotFound {
+		// Expired and cleaned up between the query and the transaction
+		err = errHoldExpired
+	}
+	if errors.Is(err, errHoldExpired) {
+		apierror.Write(w, http.StatusGone, apierror.CodeVerificationExpired, "This confirmation link has expired; please register again")
+		return
+	}
+	if err != nil {
+		writeStorageError(w, r, "Failed to confirm registration", err)
+		return
+	}
+
+	if confirmed {
+		attendeeCount.invalidate()
+		eventHub.Publish(events.Event{Type: events.TypeRegistration, Data: attendee})
+	}
+	json.NewEncoder(w).Encode(RegisterResponse{Message: "Registration confirmed"})
+}
+
+// ExpirePendingRegistrations deletes pending registrations whose hold ended
+// before now, releasing their seats, and returns how many it deleted.
+func ExpirePendingRegistrations(ctx context.Context, now time.Time) (int, error) {
+	iter := firestore.GetAttendeesCollection().Where("holdExpiresAt", "<=", now).Documents(ctx)
+	defer iter.Stop()
+
+	deleted := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return deleted, nil
+		}
+		if err != nil {
+			return deleted, err
+		}
+
+		var attendee models.Attendee
+		if err := doc.DataTo(&attendee); err != nil || attendee.Status != models.StatusPendingVerification {
+			continue
+		}
+		attendee.ID = doc.Ref.ID
+
+		// Skip registrations confirmed or renewed since they were read
+		_, err = doc.Ref.Delete(ctx, gcfirestore.LastUpdateTime(doc.UpdateTime))
+		if status.Code(err) == codes.FailedPrecondition {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+		eventHub.Publish(events.Event{Type: events.TypeExpiry, Data: attendee})
+	}
+}
+
+// RunVerificationCleanup deletes expired pending registrations every
+// verificationCleanupInterval until ctx is done.
+func RunVerificationCleanup(ctx context.Context) {
+	ticker := time.NewTicker(verificationCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := ExpirePendingRegistrations(ctx, now)
+			if err != nil {
+				log.Printf("Failed to expire pending registrations: %v", err)
+			}
+			if n > 0 {
+				log.Printf("Expired %d pending registrations", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"event-registration-backend/apierror"
+	"event-registration-backend/models"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVerificationToken(t *testing.T) {
+	token, hash, err := newVerificationToken()
+	require.NoError(t, err)
+
+	assert.Len(t, token, 43)
+	assert.Equal(t, hashVerificationToken(token), hash)
+	assert.NotContains(t, hash, token)
+
+	other, _, err := newVerificationToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestVerificationEmail(t *testing.T) {
+	prev := Registration
+	t.Cleanup(func() { Registration = prev })
+	Registration.PublicURL = "https://events.example.com"
+
+	hold := time.Date(2026, time.November, 2, 9, 30, 0, 0, time.UTC)
+	m := verificationEmail(models.Attendee{FullName: "Ada", Email: "ada@example.com", HoldExpiresAt: &hold}, "tok-en_1")
+
+	assert.Equal(t, "ada@example.com", m.To)
+	assert.Contains(t, m.Body, "Hi Ada,")
+	assert.Contains(t, m.Body, "https://events.example.com/verify?token=tok-en_1\n")
+	assert.Contains(t, m.Body, "held until Mon 2 Nov 2026 09:30 UTC")
+}
+
+func TestHoldActive(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+
+	assert.True(t, holdActive(&models.Attendee{HoldExpiresAt: &later}, now))
+	assert.False(t, holdActive(&models.Attendee{HoldExpiresAt: &earlier}, now))
+	assert.False(t, holdActive(&models.Attendee{}, now))
+}
+
+func TestResendWait(t *testing.T) {
+	now := time.Now()
+	recent, old := now.Add(-time.Minute), now.Add(-time.Hour)
+
+	assert.Equal(t, verificationResendInterval-time.Minute, resendWait(&models.Attendee{CreatedAt: old, VerificationSentAt: &recent}, now))
+	assert.Zero(t, resendWait(&models.Attendee{CreatedAt: recent, VerificationSentAt: &old}, now))
+	// Registrations from before VerificationSentAt count from when they were made
+	assert.Equal(t, verificationResendInterval-time.Minute, resendWait(&models.Attendee{CreatedAt: recent}, now))
+	assert.Zero(t, resendWait(&models.Attendee{CreatedAt: old}, now))
+}
+
+func TestVerifyRegistration_RequiresToken(t *testing.T) {
+	for _, body := range []string{`{}`, `{"token":"` + strings.Repeat("a", 129) + `"}`} {
+		w := httptest.NewRecorder()
+		VerifyRegistration(w, httptest.NewRequest("POST", "/api/v1/register/verify", bytes.NewBufferString(body)))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var envelope apierror.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+		assert.Equal(t, map[string]string{"token": "Token is required"}, envelope.Fields)
+	}
+}
+
+func TestVerificationLinkSurvivesQueryParsing(t *testing.T) {
+	token, _, err := newVerificationToken()
+	require.NoError(t, err)
+	hold := time.Now()
+	m := verificationEmail(models.Attendee{HoldExpiresAt: &hold}, token)
+
+	start := strings.Index(m.Body, "http")
+	link, err := url.Parse(strings.Fields(m.Body[start:])[0])
+	require.NoError(t, err)
+	assert.Equal(t, token, link.Query().Get("token"))
+}
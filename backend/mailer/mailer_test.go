@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	s := SMTP{From: "Events <events@example.com>"}
+	now := time.Date(2026, time.November, 1, 9, 0, 0, 0, time.UTC)
+
+	msg, err := s.compose(Message{To: "ada@example.com", Subject: "Confirm your registration ✓", Body: "Hello\nLine two"}, now)
+	require.NoError(t, err)
+
+	head, body, ok := strings.Cut(string(msg), "\r\n\r\n")
+	require.True(t, ok)
+	assert.Contains(t, head, "From: \"Events\" <events@example.com>\r\n")
+	assert.Contains(t, head, "To: <ada@example.com>\r\n")
+	assert.Contains(t, head, "Subject: =?utf-8?q?Confirm_your_registration_=E2=9C=93?=\r\n")
+	assert.Contains(t, head, "Date: Sun, 01 Nov 2026 09:00:00 +0000\r\n")
+	assert.Equal(t, "Hello\r\nLine two", body)
+}
+
+func TestCompose_RejectsHeaderInjection(t *testing.T) {
+	s := SMTP{From: "events@example.com"}
+
+	_, err := s.compose(Message{To: "ada@example.com\r\nBcc: everyone@example.com", Subject: "Hi"}, time.Now())
+	assert.Error(t, err)
+	_, err = s.compose(Message{To: "ada@example.com", Subject: "Hi\r\nBcc: everyone@example.com"}, time.Now())
+	assert.Error(t, err)
+}
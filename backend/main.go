@@ -1,14 +1,26 @@
 package main
 
 import (
+	"context"
+	"event-registration-backend/apierror"
+	"event-registration-backend/challenge"
 	"event-registration-backend/config"
 	"event-registration-backend/firestore"
 	"event-registration-backend/handlers"
+	"event-registration-backend/mailer"
 	"event-registration-backend/middleware"
+	"event-registration-backend/migrations"
+	"event-registration-backend/static"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	_ "time/tzdata" // time zone database for attendee exports in minimal images
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -26,64 +38,152 @@ func main() {
 		log.Fatalf("Failed to initialize Firestore: %v", err)
 	}
 
+	// Bring stored documents up to the current schema
+	if cfg.MigrateOnStartup {
+		ev := migrations.NewEvent(firestore.Client, cfg.ClientID)
+		ran, err := migrations.Run(context.Background(), ev, migrations.NewFirestoreLedger(ev), migrations.All())
+		if err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+		if len(ran) > 0 {
+			log.Printf("Applied migrations: %v", ran)
+		}
+	}
+
 	// Setup router
 	r := mux.NewRouter()
+	r.NotFoundHandler = http.HandlerFunc(apierror.NotFound)
+	r.MethodNotAllowedHandler = http.HandlerFunc(apierror.MethodNotAllowed)
+	if err := registerAPIRoutes(r, cfg); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	registerHealthRoutes(r)
 
-	// Public API routes
-	r.HandleFunc("/api/sessions", handlers.GetSessions).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/speakers", handlers.GetSpeakers).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/register", handlers.RegisterAttendee).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/attendees/count", handlers.GetAttendeeCount).Methods("GET", "OPTIONS")
-
-	// Admin routes
-	r.HandleFunc("/api/admin/login", handlers.AdminLogin).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/admin/attendees", handlers.AdminAuthMiddleware(handlers.GetAttendees)).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/admin/stats", handlers.AdminAuthMiddleware(handlers.GetStats)).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/admin/speakers", handlers.AdminAuthMiddleware(handlers.AddUpdateSpeaker)).Methods("POST", "OPTIONS")
-	r.HandleFunc("/api/admin/sessions", handlers.AdminAuthMiddleware(handlers.AddUpdateSession)).Methods("POST", "OPTIONS")
-
-	// Serve static files (frontend)
-	staticDir := "./static"
-	if _, err := os.Stat(staticDir); err == nil {
-		fs := http.FileServer(http.Dir(staticDir))
-		
-		// Serve static assets (JS, CSS, images, etc.)
-		r.PathPrefix("/assets/").Handler(http.StripPrefix("/assets/", fs))
-		
-		// Serve other static files like favicon
-		r.PathPrefix("/vite.svg").Handler(fs)
-		
-		// Serve index.html for all non-API routes (SPA routing)
+	// Storage calls end with the request, or at these deadlines
+	handlers.Timeouts = handlers.StorageTimeouts{
+		Request: cfg.StorageTimeout,
+		Bulk:    cfg.BulkStorageTimeout,
+	}
+
+	// Registrations are confirmed by email before they take a seat
+	handlers.Registration = handlers.RegistrationSettings{
+		Mailer:    mailer.Log{},
+		PublicURL: cfg.PublicURL,
+		Hold:      cfg.VerificationHold,
+		Capacity:  cfg.EventCapacity,
+	}
+	if cfg.SMTPHost != "" {
+		handlers.Registration.Mailer = mailer.SMTP{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}
+	} else {
+		log.Println("SMTP_HOST not set; verification emails will be written to the log")
+	}
+	// Background work stops with the server and is waited for before exit
+	background, stopBackground := context.WithCancel(context.Background())
+	var backgroundWork sync.WaitGroup
+	backgroundWork.Add(2)
+	go func() {
+		defer backgroundWork.Done()
+		handlers.RunVerificationCleanup(background)
+	}()
+	go func() {
+		defer backgroundWork.Done()
+		handlers.WatchCheckIns(background)
+	}()
+
+	// Require a proof of work with each public registration
+	if cfg.RegistrationPoWDifficulty > 0 {
+		handlers.RegistrationChallenge = challenge.ProofOfWork{Difficulty: cfg.RegistrationPoWDifficulty}
+	}
+
+	// Serve the frontend, falling back to index.html for client-side routes.
+	// A build in STATIC_DIR takes precedence over one embedded with
+	// -tags embedfrontend.
+	var site fs.FS
+	if cfg.StaticDir != "" {
+		if dir, ok := static.Dir(cfg.StaticDir); ok {
+			site = dir
+			log.Printf("Static file serving enabled from %s", cfg.StaticDir)
+		} else {
+			log.Printf("STATIC_DIR %s has no index.html, ignoring it", cfg.StaticDir)
+		}
+	}
+	if site == nil {
+		if embedded, ok := static.Embedded(); ok {
+			site = embedded
+			log.Println("Static file serving enabled from the embedded frontend")
+		} else {
+			log.Println("No frontend build found, skipping static file serving")
+		}
+	}
+	if site != nil {
+		siteHandler := static.Handler(site)
 		// This must be registered last to catch all remaining routes
 		r.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			// Skip API routes
-			if filepath.HasPrefix(req.URL.Path, "/api") {
-				http.NotFound(w, req)
+			if req.URL.Path == "/api" || strings.HasPrefix(req.URL.Path, "/api/") {
+				apierror.NotFound(w, req)
 				return
 			}
-			
-			// Check if requested file exists
-			requestedPath := filepath.Join(staticDir, req.URL.Path)
-			if info, err := os.Stat(requestedPath); err == nil && !info.IsDir() {
-				// File exists, serve it
-				fs.ServeHTTP(w, req)
-			} else {
-				// File doesn't exist, serve index.html for SPA routing
-				http.ServeFile(w, req, filepath.Join(staticDir, "index.html"))
-			}
+			siteHandler.ServeHTTP(w, req)
 		})
-		log.Println("Static file serving enabled from ./static")
-	} else {
-		log.Println("Static directory not found, skipping static file serving")
 	}
 
-	// CORS middleware - wrap the router
-	corsHandler := middleware.CORS(r)
+	// Wrap the router: tag every request with a correlation ID, add security
+	// headers, then CORS
+	cors := middleware.CORSOptions{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}
+	handlers.FeedOriginAllowed = cors.OriginAllowed
+	csp := cfg.ContentSecurityPolicy
+	if csp == "" {
+		csp = middleware.DefaultContentSecurityPolicy
+	}
+	security := middleware.SecurityHeaders(middleware.SecurityOptions{
+		ContentSecurityPolicy: csp,
+		HSTSMaxAge:            cfg.HSTSMaxAge,
+	})
+	handler := middleware.RequestID(security(middleware.CORS(cors)(r)))
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	srv.RegisterOnShutdown(handlers.CloseStreams)
 
 	// Start server
-	log.Printf("Server starting on port %s", cfg.Port)
-	if err := http.ListenAndServe(":"+cfg.Port, corsHandler); err != nil {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
 		log.Fatalf("Server failed to start: %v", err)
 	}
-}
+	log.Printf("Server starting on port %s", cfg.Port)
+
+	// Drain connections on SIGTERM, which container runtimes send on restart
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	exitCode := 0
+	if err := serve(ctx, srv, ln, cfg.ShutdownTimeout); err != nil {
+		log.Printf("Server error: %v", err)
+		exitCode = 1
+	}
+	stop()
 
+	stopBackground()
+	backgroundWork.Wait()
+	if err := firestore.Client.Close(); err != nil {
+		log.Printf("Failed to close Firestore client: %v", err)
+	}
+	log.Println("Server stopped")
+	os.Exit(exitCode)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"event-registration-backend/config"
+	"event-registration-backend/firestore"
+	"event-registration-backend/openapi"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	gcfirestore "cloud.google.com/go/firestore"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// routesUnder lists "METHOD path" for every non-OPTIONS route whose path
+// starts with prefix.
+func routesUnder(t *testing.T, r *mux.Router, prefix string) []string {
+	var routes []string
+	err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil // a subrouter's prefix route
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(path, prefix) {
+			return nil
+		}
+		for _, method := range methods {
+			if method != "OPTIONS" {
+				routes = append(routes, method+" "+path)
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(routes)
+	return routes
+}
+
+// TestRoutesMatchOpenAPI fails when a route is added, removed or changes
+// method without the OpenAPI document following.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	r := mux.NewRouter()
+	require.NoError(t, registerAPIRoutes(r, config.LoadConfig()))
+
+	documented := make(map[string]bool)
+	for path, item := range openapi.Build().Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	routed := make(map[string]bool)
+	for _, route := range routesUnder(t, r, "/api/v1/") {
+		routed[route] = true
+		if !documented[route] {
+			t.Errorf("route %s has no operation in the OpenAPI spec", route)
+		}
+	}
+	for op := range documented {
+		if !routed[op] {
+			t.Errorf("OpenAPI operation %s has no route", op)
+		}
+	}
+}
+
+// TestRequestBodiesMatchOpenAPI sends every documented JSON request body to
+// the route's real handler. A body built from the spec's schema must not be
+// refused for unknown or mistyped fields, and a field the spec does not
+// declare must be refused, so the spec describes the type each handler
+// actually decodes.
+func TestRequestBodiesMatchOpenAPI(t *testing.T) {
+	useFailingFirestore(t)
+	t.Setenv("ADMIN_PASSWORD", "admin123")
+	r := mux.NewRouter()
+	require.NoError(t, registerAPIRoutes(r, config.LoadConfig()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/admin/login", strings.NewReader(`{"password":"admin123"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var login struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+
+	spec := openapi.Build()
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+login.Token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			if op.RequestBody == nil || op.RequestBody.Content["application/json"] == nil {
+				continue
+			}
+			method := strings.ToUpper(method)
+			t.Run(method+" "+path, func(t *testing.T) {
+				schema := resolveSchema(spec, op.RequestBody.Content["application/json"].Schema)
+				body := make(map[string]interface{}, len(schema.Properties))
+				for name, property := range schema.Properties {
+					body[name] = zeroValue(resolveSchema(spec, property))
+				}
+
+				w := send(method, path, body)
+				assert.NotContains(t, strings.ToLower(w.Body.String()), "unknown field", "the handler refuses a documented field")
+				assert.NotContains(t, w.Body.String(), "wrong type", "the handler decodes a documented field as another type")
+
+				body["undocumentedField"] = true
+				w = send(method, path, body)
+				assert.Equal(t, http.StatusBadRequest, w.Code, "the handler accepts a field the spec does not declare")
+				assert.Contains(t, w.Body.String(), "undocumentedField")
+			})
+		}
+	}
+}
+
+// resolveSchema follows a component reference.
+func resolveSchema(spec *openapi.Document, s *openapi.Schema) *openapi.Schema {
+	if name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/"); ok {
+		return spec.Components.Schemas[name]
+	}
+	return s
+}
+
+// zeroValue is the JSON zero value of a schema's type.
+func zeroValue(s *openapi.Schema) interface{} {
+	if s.Nullable {
+		return nil
+	}
+	switch s.Type {
+	case "string":
+		if len(s.Enum) > 0 {
+			return s.Enum[0]
+		}
+		if s.Format == "date-time" {
+			return time.Time{}.Format(time.RFC3339)
+		}
+		return ""
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		return []interface{}{}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// useFailingFirestore points the firestore package at a server that fails
+// every call, for the duration of the test.
+func useFailingFirestore(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(interface{}, grpc.ServerStream) error {
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}))
+	go srv.Serve(lis)
+
+	t.Setenv("FIRESTORE_EMULATOR_HOST", lis.Addr().String())
+	client, err := gcfirestore.NewClient(context.Background(), "test-project")
+	require.NoError(t, err)
+
+	prevClient, prevID := firestore.Client, firestore.ClientID
+	firestore.Client, firestore.ClientID = client, "test-event"
+	t.Cleanup(func() {
+		firestore.Client, firestore.ClientID = prevClient, prevID
+		client.Close()
+		srv.Stop()
+	})
+}
+
+func TestLegacyAPIMirrorsV1(t *testing.T) {
+	r := mux.NewRouter()
+	require.NoError(t, registerAPIRoutes(r, config.LoadConfig()))
+
+	var legacy []string
+	for _, route := range routesUnder(t, r, "/api/") {
+		if !strings.Contains(route, " /api/v1/") {
+			legacy = append(legacy, strings.Replace(route, " /api/", " /api/v1/", 1))
+		}
+	}
+	sort.Strings(legacy)
+
+	assert.Equal(t, routesUnder(t, r, "/api/v1/"), legacy)
+}
+
+func TestLegacyAPIDeprecationHeaders(t *testing.T) {
+	r := mux.NewRouter()
+	require.NoError(t, registerAPIRoutes(r, config.LoadConfig()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1/openapi.json>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+}
+
+func TestHealthRoutes(t *testing.T) {
+	r := mux.NewRouter()
+	require.NoError(t, registerAPIRoutes(r, config.LoadConfig()))
+	registerHealthRoutes(r)
+
+	for _, method := range []string{"GET", "HEAD"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, "/healthz", nil))
+		assert.Equal(t, http.StatusOK, w.Code, method)
+	}
+
+	// Not under /api, so not deprecated
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Empty(t, w.Header().Get("Deprecation"))
+}
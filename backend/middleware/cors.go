@@ -1,19 +1,139 @@
 package middleware
 
-import "net/http"
+import (
+	"event-registration-backend/apierror"
+	"event-registration-backend/requestid"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
 
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// CORSOptions lists what cross-origin browser requests may do. Same-origin
+// requests need no CORS headers and are unaffected.
+type CORSOptions struct {
+	// AllowedOrigins are origins such as "https://events.example.com", or
+	// "*" for any origin. Empty allows none.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// OriginAllowed reports whether origin may make cross-origin requests.
+func (o CORSOptions) OriginAllowed(origin string) bool {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
 		}
+	}
+	return false
+}
 
-		next.ServeHTTP(w, r)
-	})
+func (o CORSOptions) methodAllowed(method string) bool {
+	if method == http.MethodOptions {
+		return true
+	}
+	for _, allowed := range o.AllowedMethods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
 }
 
+// headersAllowed reports whether every header in a preflight's
+// Access-Control-Request-Headers list is allowed.
+func (o CORSOptions) headersAllowed(requested string) bool {
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		found := false
+		for _, allowed := range o.AllowedHeaders {
+			if allowed == "*" || strings.EqualFold(allowed, h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// allowOrigin is the Access-Control-Allow-Origin value for origin. A
+// wildcard is echoed as the origin when credentials are allowed, since
+// browsers reject "*" on credentialed requests.
+func (o CORSOptions) allowOrigin(origin string) string {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" && !o.AllowCredentials {
+			return "*"
+		}
+	}
+	return origin
+}
+
+// CORS adds CORS headers to responses for allowed origins and answers
+// their preflight requests. Preflights from other origins are refused with
+// 403; other requests pass through without CORS headers, so the browser
+// withholds the response from the calling page.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Responses differ by origin, so caches must key on it
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			if origin == "" || SameOrigin(r, origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				if !opts.OriginAllowed(origin) ||
+					!opts.methodAllowed(r.Header.Get("Access-Control-Request-Method")) ||
+					!opts.headersAllowed(r.Header.Get("Access-Control-Request-Headers")) {
+					apierror.Write(w, http.StatusForbidden, apierror.CodeForbidden, "Cross-origin request not allowed")
+					return
+				}
+				w.Header().Set("Access-Control-Allow-Origin", opts.allowOrigin(origin))
+				if opts.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if opts.OriginAllowed(origin) {
+				w.Header().Set("Access-Control-Allow-Origin", opts.allowOrigin(origin))
+				if opts.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				w.Header().Set("Access-Control-Expose-Headers", "Retry-After, "+requestid.Header+", Deprecation, Sunset, Link")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SameOrigin reports whether origin names the host r was sent to. Browsers
+// send Origin on same-origin POSTs too, and those need no CORS headers.
+func SameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Deprecated marks every response as coming from a deprecated API
+// (RFC 9745) that stops working at sunset (RFC 8594). successor maps the
+// request path to its replacement, advertised in a Link header; it may
+// return "" when there is none.
+func Deprecated(since, sunset time.Time, successor func(path string) string) func(http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunsetDate)
+			if link := successor(r.URL.Path); link != "" {
+				w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", link))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -2,84 +2,297 @@ package middleware_test
 
 import (
 	"event-registration-backend/middleware"
+	"event-registration-backend/requestid"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+var testCORS = middleware.CORSOptions{
+	AllowedOrigins: []string{"https://dashboard.example.com"},
+	AllowedMethods: []string{"GET", "POST"},
+	AllowedHeaders: []string{"Content-Type", "Authorization"},
+	MaxAge:         10 * time.Minute,
+}
+
 func TestCORS(t *testing.T) {
 	tests := []struct {
 		name           string
+		opts           middleware.CORSOptions
 		method         string
+		headers        map[string]string
 		expectedStatus int
-		checkHeaders   func(*testing.T, *httptest.ResponseRecorder)
+		expectNext     bool
+		checkHeaders   func(*testing.T, http.Header)
 	}{
 		{
-			name:           "OPTIONS request",
-			method:         "OPTIONS",
+			name:           "no Origin",
+			method:         "GET",
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+			checkHeaders: func(t *testing.T, h http.Header) {
+				assert.Empty(t, h.Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "Origin", h.Get("Vary"))
+			},
+		},
+		{
+			name:           "same origin",
+			method:         "POST",
+			headers:        map[string]string{"Origin": "http://example.com"},
 			expectedStatus: http.StatusOK,
-			checkHeaders: func(t *testing.T, w *httptest.ResponseRecorder) {
-				assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-				assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-				assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+			expectNext:     true,
+			checkHeaders: func(t *testing.T, h http.Header) {
+				assert.Empty(t, h.Get("Access-Control-Allow-Origin"))
 			},
 		},
 		{
-			name:           "GET request",
+			name:           "allowed origin",
 			method:         "GET",
+			headers:        map[string]string{"Origin": "https://dashboard.example.com"},
 			expectedStatus: http.StatusOK,
-			checkHeaders: func(t *testing.T, w *httptest.ResponseRecorder) {
-				assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-				assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-				assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+			expectNext:     true,
+			checkHeaders: func(t *testing.T, h http.Header) {
+				assert.Equal(t, "https://dashboard.example.com", h.Get("Access-Control-Allow-Origin"))
+				assert.Empty(t, h.Get("Access-Control-Allow-Credentials"))
+				assert.Contains(t, h.Get("Access-Control-Expose-Headers"), "X-Request-ID")
 			},
 		},
 		{
-			name:           "POST request",
-			method:         "POST",
+			name:           "other origin gets no CORS headers",
+			method:         "GET",
+			headers:        map[string]string{"Origin": "https://evil.example"},
 			expectedStatus: http.StatusOK,
-			checkHeaders: func(t *testing.T, w *httptest.ResponseRecorder) {
-				assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+			expectNext:     true,
+			checkHeaders: func(t *testing.T, h http.Header) {
+				assert.Empty(t, h.Get("Access-Control-Allow-Origin"))
+			},
+		},
+		{
+			name:   "preflight from allowed origin",
+			method: "OPTIONS",
+			headers: map[string]string{
+				"Origin":                         "https://dashboard.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "content-type, authorization",
+			},
+			expectedStatus: http.StatusNoContent,
+			checkHeaders: func(t *testing.T, h http.Header) {
+				assert.Equal(t, "https://dashboard.example.com", h.Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "GET, POST", h.Get("Access-Control-Allow-Methods"))
+				assert.Equal(t, "Content-Type, Authorization", h.Get("Access-Control-Allow-Headers"))
+				assert.Equal(t, "600", h.Get("Access-Control-Max-Age"))
+			},
+		},
+		{
+			name:           "preflight from other origin",
+			method:         "OPTIONS",
+			headers:        map[string]string{"Origin": "https://evil.example", "Access-Control-Request-Method": "POST"},
+			expectedStatus: http.StatusForbidden,
+			checkHeaders: func(t *testing.T, h http.Header) {
+				assert.Empty(t, h.Get("Access-Control-Allow-Origin"))
+			},
+		},
+		{
+			name:           "preflight for a method not allowed",
+			method:         "OPTIONS",
+			headers:        map[string]string{"Origin": "https://dashboard.example.com", "Access-Control-Request-Method": "DELETE"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "preflight for a header not allowed",
+			method: "OPTIONS",
+			headers: map[string]string{
+				"Origin":                         "https://dashboard.example.com",
+				"Access-Control-Request-Method":  "GET",
+				"Access-Control-Request-Headers": "X-Custom",
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "plain OPTIONS is routed as usual",
+			method:         "OPTIONS",
+			headers:        map[string]string{"Origin": "https://dashboard.example.com"},
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+		},
+		{
+			name:           "wildcard",
+			opts:           middleware.CORSOptions{AllowedOrigins: []string{"*"}},
+			method:         "GET",
+			headers:        map[string]string{"Origin": "https://anywhere.example"},
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+			checkHeaders: func(t *testing.T, h http.Header) {
+				assert.Equal(t, "*", h.Get("Access-Control-Allow-Origin"))
+			},
+		},
+		{
+			name:           "wildcard with credentials echoes the origin",
+			opts:           middleware.CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			method:         "GET",
+			headers:        map[string]string{"Origin": "https://anywhere.example"},
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+			checkHeaders: func(t *testing.T, h http.Header) {
+				assert.Equal(t, "https://anywhere.example", h.Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "true", h.Get("Access-Control-Allow-Credentials"))
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req := httptest.NewRequest(tt.method, "/api/test", nil)
+			opts := tt.opts
+			if opts.AllowedOrigins == nil {
+				opts = testCORS
+			}
+			req := httptest.NewRequest(tt.method, "http://example.com/api/v1/test", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
 			w := httptest.NewRecorder()
 
+			called := false
 			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
 				w.WriteHeader(http.StatusOK)
 			})
-
-			corsHandler := middleware.CORS(nextHandler)
-			corsHandler.ServeHTTP(w, req)
+			middleware.CORS(opts)(nextHandler).ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectNext, called)
 			if tt.checkHeaders != nil {
-				tt.checkHeaders(t, w)
+				tt.checkHeaders(t, w.Header())
 			}
 		})
 	}
 }
 
-func TestCORS_NextHandlerCalled(t *testing.T) {
-	req := httptest.NewRequest("GET", "/api/test", nil)
+func TestCORSOptions_OriginAllowed(t *testing.T) {
+	assert.True(t, testCORS.OriginAllowed("https://dashboard.example.com"))
+	assert.True(t, testCORS.OriginAllowed("HTTPS://Dashboard.Example.com"))
+	assert.False(t, testCORS.OriginAllowed("https://dashboard.example.com.evil.example"))
+	assert.False(t, middleware.CORSOptions{}.OriginAllowed("https://dashboard.example.com"))
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestid.FromContext(r.Context())
+	}))
+
 	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, w.Header().Get(requestid.Header))
+
+	// A well-formed ID from a proxy is kept; anything else is replaced.
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(requestid.Header, "lb-1234.abc")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "lb-1234.abc", seen)
+
+	req.Header.Set(requestid.Header, "bad id\nInjected: yes")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Len(t, seen, 32)
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := middleware.NewRateLimiter(1, 2, nil)
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	send := func(method, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/register", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// The burst is allowed, then the client has to wait for a token
+	assert.Equal(t, http.StatusCreated, send("POST", "203.0.113.7:1111").Code)
+	assert.Equal(t, http.StatusCreated, send("POST", "203.0.113.7:2222").Code)
+	limited := send("POST", "203.0.113.7:3333")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Contains(t, limited.Body.String(), `"code":"rate_limited"`)
+	retryAfter, err := strconv.Atoi(limited.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.InDelta(t, 60, retryAfter, 1)
 
-	called := false
-	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		called = true
-		w.WriteHeader(http.StatusOK)
-	})
+	// Preflights and other clients are not affected
+	assert.Equal(t, http.StatusCreated, send("OPTIONS", "203.0.113.7:4444").Code)
+	assert.Equal(t, http.StatusCreated, send("POST", "198.51.100.1:1111").Code)
+}
 
-	corsHandler := middleware.CORS(nextHandler)
-	corsHandler.ServeHTTP(w, req)
+func TestClientIP(t *testing.T) {
+	trusted, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
 
-	assert.True(t, called, "Next handler should be called")
-	assert.Equal(t, http.StatusOK, w.Code)
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:1234", want: "203.0.113.7"},
+		{name: "untrusted peer cannot spoof", remoteAddr: "203.0.113.7:1234", forwarded: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:1234", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "client-supplied entries are skipped", remoteAddr: "10.1.2.3:1234", forwarded: []string{"1.1.1.1, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "proxy chain", remoteAddr: "10.1.2.3:1234", forwarded: []string{"198.51.100.1, 192.0.2.1", "10.9.9.9"}, want: "198.51.100.1"},
+		{name: "garbage stops the walk", remoteAddr: "10.1.2.3:1234", forwarded: []string{"198.51.100.1, nonsense, 10.0.0.5"}, want: "10.0.0.5"},
+		{name: "IPv6", remoteAddr: "[2001:db8::1]:1234", want: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			assert.Equal(t, tt.want, middleware.ClientIP(req, trusted))
+		})
+	}
 }
 
+func TestParseTrustedProxies(t *testing.T) {
+	nets, err := middleware.ParseTrustedProxies([]string{"127.0.0.1", "::1", "10.0.0.0/8"})
+	require.NoError(t, err)
+	assert.Len(t, nets, 3)
+
+	_, err = middleware.ParseTrustedProxies([]string{"10.0.0.0/99"})
+	assert.Error(t, err)
+	_, err = middleware.ParseTrustedProxies([]string{"proxy.internal"})
+	assert.Error(t, err)
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	handler := middleware.SecurityHeaders(middleware.SecurityOptions{
+		ContentSecurityPolicy: middleware.DefaultContentSecurityPolicy,
+		HSTSMaxAge:            180 * 24 * time.Hour,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, middleware.DefaultContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+	assert.Contains(t, w.Header().Get("Content-Security-Policy"), "frame-ancestors 'none'")
+	assert.Equal(t, "max-age=15552000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", w.Header().Get("Referrer-Policy"))
+
+	// Both are optional
+	handler = middleware.SecurityHeaders(middleware.SecurityOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"event-registration-backend/apierror"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter keeps a token bucket per client IP. A full bucket holds burst
+// tokens, each request takes one, and tokens are added back at a steady rate.
+type RateLimiter struct {
+	rate    float64 // tokens per second
+	burst   float64
+	trusted []*net.IPNet
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows perMinute requests a minute per client with bursts
+// of up to burst. X-Forwarded-For is only believed when the connection
+// comes from one of trustedProxies.
+func NewRateLimiter(perMinute, burst int, trustedProxies []*net.IPNet) *RateLimiter {
+	return &RateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(max(burst, 1)),
+		trusted: trustedProxies,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from key's bucket. When the bucket is empty it
+// reports how long until the next token.
+func (l *RateLimiter) allow(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops buckets that have refilled completely, since they behave
+// exactly like a new bucket. It runs at most once per refill period so the
+// map stays bounded by the number of recently active clients.
+func (l *RateLimiter) sweep(now time.Time) {
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.swept) < full {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+	l.swept = now
+}
+
+// Limit rejects requests beyond the client's allowance with 429 and a
+// Retry-After header. Preflight requests are not counted.
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || l.rate <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ok, wait := l.allow(ClientIP(r, l.trusted))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			apierror.Write(w, http.StatusTooManyRequests, apierror.CodeRateLimited, "Too many requests, please try again later")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientIP returns the address of the client that sent r. Behind trusted
+// proxies it is the rightmost X-Forwarded-For entry that is not itself a
+// trusted proxy; entries further left are set by the client and could be
+// forged.
+func ClientIP(r *http.Request, trusted []*net.IPNet) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if !isTrusted(remote, trusted) {
+		return remote
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// A malformed entry means nothing further left can be trusted
+			break
+		}
+		if !isTrusted(hop, trusted) {
+			return hop
+		}
+		remote = hop
+	}
+	return remote
+}
+
+func isTrusted(addr string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges such as
+// "10.0.0.0/8" or "127.0.0.1".
+func ParseTrustedProxies(entries []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"event-registration-backend/requestid"
+	"net/http"
+)
+
+// RequestID gives every request a correlation ID, reusing a well-formed
+// X-Request-ID from the client or a proxy, and echoes it in the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+	"event-registration-backend/models"
+	"log"
+
+	"cloud.google.com/go/firestore"
+)
+
+func init() {
+	Register(Migration{
+		ID:          "0001_schema_version",
+		Description: "stamp schemaVersion 1 on all documents and backfill attendee status",
+		Up:          schemaVersion1,
+	})
+}
+
+func schemaVersion1(ctx context.Context, ev *Event) error {
+	for _, c := range []struct {
+		name string
+		fn   func(map[string]interface{}) []firestore.Update
+	}{
+		{"attendees", attendeeV1},
+		{"speakers", stampV1},
+		{"sessions", stampV1},
+	} {
+		n, err := rewrite(ctx, ev, c.name, c.fn)
+		if err != nil {
+			return err
+		}
+		log.Printf("migration 0001_schema_version: updated %d %s", n, c.name)
+	}
+	return nil
+}
+
+// attendeeV1 gives attendees registered before statuses existed the
+// "registered" status, and stamps the schema version.
+func attendeeV1(data map[string]interface{}) []firestore.Update {
+	updates := stampV1(data)
+	if status, _ := data["status"].(string); status == "" {
+		updates = append(updates, firestore.Update{Path: "status", Value: models.StatusRegistered})
+	}
+	return updates
+}
+
+func stampV1(data map[string]interface{}) []firestore.Update {
+	if schemaVersionOf(data) >= 1 {
+		return nil
+	}
+	return []firestore.Update{{Path: "schemaVersion", Value: 1}}
+}
+
+// schemaVersionOf reads a document's schemaVersion, treating a missing
+// field as version 0. Firestore returns integers as int64.
+func schemaVersionOf(data map[string]interface{}) int64 {
+	v, _ := data["schemaVersion"].(int64)
+	return v
+}
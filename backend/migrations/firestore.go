@@ -0,0 +1,97 @@
+package migrations
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// batchSize is Firestore's limit on writes per transaction.
+const batchSize = 500
+
+// NewEvent returns the Event stored at clients/{clientID}.
+func NewEvent(client *firestore.Client, clientID string) *Event {
+	return &Event{
+		Client: client,
+		Root:   client.Collection("clients").Doc(clientID),
+	}
+}
+
+// FirestoreLedger records applied migrations in the event's "migrations"
+// collection, one document per migration ID.
+type FirestoreLedger struct {
+	ref *firestore.CollectionRef
+}
+
+func NewFirestoreLedger(ev *Event) *FirestoreLedger {
+	return &FirestoreLedger{ref: ev.Root.Collection("migrations")}
+}
+
+func (l *FirestoreLedger) Applied(ctx context.Context) (map[string]Record, error) {
+	applied := make(map[string]Record)
+	iter := l.ref.Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return applied, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var rec Record
+		if err := doc.DataTo(&rec); err != nil {
+			return nil, err
+		}
+		applied[doc.Ref.ID] = rec
+	}
+}
+
+func (l *FirestoreLedger) Record(ctx context.Context, rec Record) error {
+	_, err := l.ref.Doc(rec.ID).Set(ctx, rec)
+	return err
+}
+
+// update is one document's pending change.
+type update struct {
+	ref     *firestore.DocumentRef
+	updates []firestore.Update
+}
+
+// rewrite reads every document in collection, asks fn what should change,
+// and applies the non-empty answers in transactions of up to batchSize
+// writes. It returns the number of documents changed.
+func rewrite(ctx context.Context, ev *Event, collection string, fn func(data map[string]interface{}) []firestore.Update) (int, error) {
+	var changes []update
+	iter := ev.Root.Collection(collection).Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if u := fn(doc.Data()); len(u) > 0 {
+			changes = append(changes, update{ref: doc.Ref, updates: u})
+		}
+	}
+
+	for start := 0; start < len(changes); start += batchSize {
+		batch := changes[start:min(start+batchSize, len(changes))]
+		err := ev.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			for _, c := range batch {
+				if err := tx.Update(c.ref, c.updates); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return start, err
+		}
+	}
+	return len(changes), nil
+}
@@ -0,0 +1,114 @@
+// Package migrations brings stored event documents up to the shape the
+// current build expects.
+//
+// Each Migration has a unique, sortable ID and runs at most once per event;
+// the IDs that have run are recorded in a Ledger. Migrations must still be
+// idempotent, because two instances starting at the same time may both
+// decide a migration is pending.
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Event is the storage a migration operates on: the clients/{ClientID}
+// document and the client it belongs to.
+type Event struct {
+	Client *firestore.Client
+	Root   *firestore.DocumentRef
+}
+
+type Migration struct {
+	// ID orders migrations; use a zero-padded sequence such as
+	// "0002_rename_field" so later migrations sort after earlier ones.
+	ID          string
+	Description string
+	Up          func(ctx context.Context, ev *Event) error
+}
+
+// Record is the ledger entry written once a migration has run.
+type Record struct {
+	ID          string    `json:"id" firestore:"id"`
+	Description string    `json:"description" firestore:"description"`
+	AppliedAt   time.Time `json:"appliedAt" firestore:"appliedAt"`
+}
+
+// Ledger stores which migrations have been applied to an event.
+type Ledger interface {
+	Applied(ctx context.Context) (map[string]Record, error)
+	Record(ctx context.Context, rec Record) error
+}
+
+var registry = map[string]Migration{}
+
+// Register adds m to the set returned by All. It panics on a duplicate or
+// incomplete migration, since that is a programming error.
+func Register(m Migration) {
+	if m.ID == "" || m.Up == nil {
+		panic("migrations: migration needs an ID and an Up function")
+	}
+	if _, dup := registry[m.ID]; dup {
+		panic(fmt.Sprintf("migrations: duplicate migration %q", m.ID))
+	}
+	registry[m.ID] = m
+}
+
+// All returns the registered migrations ordered by ID.
+func All() []Migration {
+	all := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		all = append(all, m)
+	}
+	sortByID(all)
+	return all
+}
+
+func sortByID(ms []Migration) {
+	sort.Slice(ms, func(i, j int) bool { return ms[i].ID < ms[j].ID })
+}
+
+// Pending returns the migrations in ms that the ledger has no record of,
+// in the order they should run.
+func Pending(ctx context.Context, ledger Ledger, ms []Migration) ([]Migration, error) {
+	applied, err := ledger.Applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading applied migrations: %w", err)
+	}
+
+	var pending []Migration
+	for _, m := range ms {
+		if _, ok := applied[m.ID]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	sortByID(pending)
+	return pending, nil
+}
+
+// Run applies every pending migration in order, recording each one as it
+// completes. It stops at the first failure and returns the IDs applied
+// before it.
+func Run(ctx context.Context, ev *Event, ledger Ledger, ms []Migration) ([]string, error) {
+	pending, err := Pending(ctx, ledger, ms)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []string
+	for _, m := range pending {
+		if err := m.Up(ctx, ev); err != nil {
+			return ran, fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+		rec := Record{ID: m.ID, Description: m.Description, AppliedAt: time.Now().UTC()}
+		if err := ledger.Record(ctx, rec); err != nil {
+			return ran, fmt.Errorf("recording migration %s: %w", m.ID, err)
+		}
+		ran = append(ran, m.ID)
+	}
+	return ran, nil
+}
@@ -0,0 +1,111 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/firestore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLedger is an in-memory Ledger.
+type memoryLedger map[string]Record
+
+func (l memoryLedger) Applied(ctx context.Context) (map[string]Record, error) {
+	return l, nil
+}
+
+func (l memoryLedger) Record(ctx context.Context, rec Record) error {
+	l[rec.ID] = rec
+	return nil
+}
+
+func TestAll_OrderedByID(t *testing.T) {
+	all := All()
+	require.NotEmpty(t, all)
+	assert.Equal(t, "0001_schema_version", all[0].ID)
+	for i := 1; i < len(all); i++ {
+		assert.Less(t, all[i-1].ID, all[i].ID)
+	}
+}
+
+func TestRegister_PanicsOnDuplicate(t *testing.T) {
+	assert.Panics(t, func() {
+		Register(Migration{ID: "0001_schema_version", Up: schemaVersion1})
+	})
+}
+
+func TestRun_AppliesPendingInOrderOnce(t *testing.T) {
+	var order []string
+	step := func(id string) Migration {
+		return Migration{ID: id, Up: func(ctx context.Context, ev *Event) error {
+			order = append(order, id)
+			return nil
+		}}
+	}
+	ms := []Migration{step("0003_c"), step("0001_a"), step("0002_b")}
+	ledger := memoryLedger{"0001_a": {ID: "0001_a"}}
+
+	ran, err := Run(context.Background(), nil, ledger, ms)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0002_b", "0003_c"}, ran)
+	assert.Equal(t, []string{"0002_b", "0003_c"}, order)
+	assert.False(t, ledger["0003_c"].AppliedAt.IsZero())
+
+	ran, err = Run(context.Background(), nil, ledger, ms)
+	require.NoError(t, err)
+	assert.Empty(t, ran)
+	assert.Len(t, order, 2)
+}
+
+func TestRun_StopsAtFailure(t *testing.T) {
+	boom := errors.New("boom")
+	ms := []Migration{
+		{ID: "0001_ok", Up: func(context.Context, *Event) error { return nil }},
+		{ID: "0002_fails", Up: func(context.Context, *Event) error { return boom }},
+		{ID: "0003_never", Up: func(context.Context, *Event) error {
+			t.Fatal("ran after a failed migration")
+			return nil
+		}},
+	}
+	ledger := memoryLedger{}
+
+	ran, err := Run(context.Background(), nil, ledger, ms)
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []string{"0001_ok"}, ran)
+	assert.NotContains(t, ledger, "0002_fails")
+}
+
+func TestAttendeeV1(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want []firestore.Update
+	}{
+		{
+			name: "legacy document",
+			data: map[string]interface{}{"fullName": "Ada"},
+			want: []firestore.Update{
+				{Path: "schemaVersion", Value: 1},
+				{Path: "status", Value: "registered"},
+			},
+		},
+		{
+			name: "status already set",
+			data: map[string]interface{}{"status": "registered"},
+			want: []firestore.Update{{Path: "schemaVersion", Value: 1}},
+		},
+		{
+			name: "already migrated",
+			data: map[string]interface{}{"status": "registered", "schemaVersion": int64(1)},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, attendeeV1(tt.data))
+		})
+	}
+}
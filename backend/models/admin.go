@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// AdminUser is a named dashboard account. The password is only ever
+// stored as a bcrypt hash.
+type AdminUser struct {
+	Username     string    `json:"username" firestore:"username"`
+	PasswordHash string    `json:"-" firestore:"passwordHash"`
+	CreatedAt    time.Time `json:"createdAt" firestore:"createdAt"`
+}
@@ -1,18 +1,74 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// Attendee registration statuses. A public registration is pending until
+// the attendee follows the link emailed to them; only registered attendees
+// hold a confirmed seat.
+const (
+	StatusPendingVerification = "pending_verification"
+	StatusRegistered          = "registered"
+)
 
 type Attendee struct {
-	ID          string    `json:"id" firestore:"id"`
-	FullName    string    `json:"fullName" firestore:"fullName"`
-	Email       string    `json:"email" firestore:"email"`
-	Designation string    `json:"designation" firestore:"designation"`
-	CreatedAt   time.Time `json:"createdAt" firestore:"createdAt"`
+	ID          string     `json:"id" firestore:"id"`
+	FullName    string     `json:"fullName" firestore:"fullName"`
+	Email       string     `json:"email" firestore:"email"`
+	Designation string     `json:"designation" firestore:"designation"`
+	Status      string     `json:"status" firestore:"status"`
+	CreatedAt   time.Time  `json:"createdAt" firestore:"createdAt"`
+	CheckedInAt *time.Time `json:"checkedInAt,omitempty" firestore:"checkedInAt,omitempty"`
+	// HoldExpiresAt is when a pending registration's seat is released. It
+	// is removed once the email is verified.
+	HoldExpiresAt *time.Time `json:"holdExpiresAt,omitempty" firestore:"holdExpiresAt,omitempty"`
+	VerifiedAt    *time.Time `json:"verifiedAt,omitempty" firestore:"verifiedAt,omitempty"`
+	// VerificationTokenHash is the SHA-256 of the emailed verification
+	// token; the token itself is never stored.
+	VerificationTokenHash string `json:"-" firestore:"verificationTokenHash,omitempty"`
+	// VerificationSentAt is when the current verification link was emailed,
+	// which limits how often a pending registration can ask for another.
+	VerificationSentAt *time.Time `json:"verificationSentAt,omitempty" firestore:"verificationSentAt,omitempty"`
+	// SchemaVersion is the document shape this attendee was written with.
+	SchemaVersion int `json:"schemaVersion,omitempty" firestore:"schemaVersion"`
 }
 
 type RegisterRequest struct {
 	FullName    string `json:"fullName"`
 	Email       string `json:"email"`
 	Designation string `json:"designation"`
+	// Website is a honeypot. The form hides it from people, so a value
+	// means the request came from a bot filling in every field.
+	Website string `json:"website,omitempty"`
+	// Challenge is the solution to the registration challenge, when one
+	// is required.
+	Challenge string `json:"challenge,omitempty"`
+}
+
+// Normalize trims surrounding space from every field and normalizes the
+// email. Call it before Validate.
+func (r *RegisterRequest) Normalize() {
+	r.FullName = strings.TrimSpace(r.FullName)
+	r.Email = NormalizeEmail(r.Email)
+	r.Designation = strings.TrimSpace(r.Designation)
+}
+
+// Validate checks the rules every registration must satisfy, whether it
+// comes from the public form or a bulk import.
+func (r RegisterRequest) Validate() error {
+	var v ValidationError
+	v.line("fullName", "Full name", r.FullName, MaxNameLength, true)
+	v.email("email", r.Email)
+	v.line("designation", "Designation", r.Designation, MaxDesignationLength, true)
+	return v.Err()
+}
+
+// AttendeePage is one page of the admin attendee list. NextCursor is empty
+// on the last page.
+type AttendeePage struct {
+	Attendees  []Attendee `json:"attendees"`
+	NextCursor string     `json:"nextCursor,omitempty"`
 }
 
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxPolicyDomains caps each domain list of an EmailDomainPolicy.
+const MaxPolicyDomains = 1000
+
+// EmailDomainPolicyID is the settings document holding the policy.
+const EmailDomainPolicyID = "emailDomains"
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// EmailDomainPolicy decides which email domains may register. A listed
+// domain also covers its subdomains, and where the lists overlap the most
+// specific entry wins: blocking bad.example.com refuses it even when
+// example.com is allowed. Allowed domains are exempt from the bundled
+// disposable-domain list; with AllowedOnly set, they are the only domains
+// that may register, as for a private event.
+type EmailDomainPolicy struct {
+	Blocked     []string   `json:"blocked" firestore:"blocked"`
+	Allowed     []string   `json:"allowed" firestore:"allowed"`
+	AllowedOnly bool       `json:"allowedOnly" firestore:"allowedOnly"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty" firestore:"updatedAt,omitempty"`
+}
+
+// Normalize lower-cases the domains, drops a leading "@" and blank entries,
+// and sorts and de-duplicates each list. Call it before Validate.
+func (p *EmailDomainPolicy) Normalize() {
+	p.Blocked = normalizeDomains(p.Blocked)
+	p.Allowed = normalizeDomains(p.Allowed)
+}
+
+func normalizeDomains(domains []string) []string {
+	seen := make(map[string]bool, len(domains))
+	out := []string{}
+	for _, d := range domains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "@")
+		if d != "" && !seen[d] {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Validate checks that every entry is a domain name, that no domain is
+// both blocked and allowed, and that AllowedOnly has domains to allow.
+func (p EmailDomainPolicy) Validate() error {
+	var v ValidationError
+	v.domains("blocked", p.Blocked)
+	v.domains("allowed", p.Allowed)
+
+	allowed := make(map[string]bool, len(p.Allowed))
+	for _, d := range p.Allowed {
+		allowed[d] = true
+	}
+	for _, d := range p.Blocked {
+		if allowed[d] {
+			v.Add("blocked", fmt.Sprintf("%s is also allowed", d))
+			break
+		}
+	}
+
+	if p.AllowedOnly && len(p.Allowed) == 0 {
+		v.Add("allowed", "At least one domain is required when only allowed domains may register")
+	}
+	return v.Err()
+}
+
+func (e *ValidationError) domains(field string, domains []string) {
+	if len(domains) > MaxPolicyDomains {
+		e.Add(field, fmt.Sprintf("At most %d domains are allowed", MaxPolicyDomains))
+		return
+	}
+	for _, d := range domains {
+		if len(d) > 253 || !domainPattern.MatchString(d) {
+			e.Add(field, fmt.Sprintf("%q is not a domain name", d))
+			return
+		}
+	}
+}
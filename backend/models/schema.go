@@ -0,0 +1,7 @@
+package models
+
+// SchemaVersion is the shape of attendee, speaker and session documents
+// written by this build. Bump it together with a migration in the
+// migrations package that brings older documents up to date.
+const SchemaVersion = 1
+
@@ -1,11 +1,15 @@
 package models
 
+import "strings"
+
 type Session struct {
 	ID          string `json:"id" firestore:"id"`
 	Title       string `json:"title" firestore:"title"`
 	Description string `json:"description" firestore:"description"`
 	Time        string `json:"time" firestore:"time"`
 	SpeakerID   string `json:"speakerId" firestore:"speakerId"`
+	// SchemaVersion is the document shape this session was written with.
+	SchemaVersion int `json:"schemaVersion,omitempty" firestore:"schemaVersion"`
 }
 
 type SessionWithSpeaker struct {
@@ -13,3 +17,25 @@ type SessionWithSpeaker struct {
 	Speaker *Speaker `json:"speaker,omitempty"`
 }
 
+// Normalize trims surrounding space from every field. Call it before
+// Validate.
+func (s *Session) Normalize() {
+	s.ID = strings.TrimSpace(s.ID)
+	s.Title = strings.TrimSpace(s.Title)
+	s.Description = strings.TrimSpace(s.Description)
+	s.Time = strings.TrimSpace(s.Time)
+	s.SpeakerID = strings.TrimSpace(s.SpeakerID)
+}
+
+// Validate checks the rules every session must satisfy, whether it comes
+// from the admin API, a bulk import or a seed file.
+func (s Session) Validate() error {
+	var v ValidationError
+	v.id("id", "ID", s.ID)
+	v.line("title", "Title", s.Title, MaxTitleLength, true)
+	v.paragraph("description", "Description", s.Description, MaxDescriptionLength)
+	v.line("time", "Time", s.Time, MaxTimeLength, false)
+	v.id("speakerId", "Speaker ID", s.SpeakerID)
+	return v.Err()
+}
+
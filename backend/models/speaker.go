@@ -1,9 +1,32 @@
 package models
 
+import "strings"
+
 type Speaker struct {
 	ID      string `json:"id" firestore:"id"`
 	Name    string `json:"name" firestore:"name"`
 	Bio     string `json:"bio" firestore:"bio"`
 	PhotoURL string `json:"photoURL" firestore:"photoURL"`
+	// SchemaVersion is the document shape this speaker was written with.
+	SchemaVersion int `json:"schemaVersion,omitempty" firestore:"schemaVersion"`
+}
+
+// Normalize trims surrounding space from every field. Call it before
+// Validate.
+func (s *Speaker) Normalize() {
+	s.ID = strings.TrimSpace(s.ID)
+	s.Name = strings.TrimSpace(s.Name)
+	s.Bio = strings.TrimSpace(s.Bio)
+	s.PhotoURL = strings.TrimSpace(s.PhotoURL)
 }
 
+// Validate checks the rules every speaker must satisfy, whether it comes
+// from the admin API, a bulk import or a seed file.
+func (s Speaker) Validate() error {
+	var v ValidationError
+	v.id("id", "ID", s.ID)
+	v.line("name", "Name", s.Name, MaxNameLength, true)
+	v.paragraph("bio", "Bio", s.Bio, MaxBioLength)
+	v.url("photoURL", "Photo URL", s.PhotoURL)
+	return v.Err()
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Field length limits, in characters.
+const (
+	MaxNameLength        = 100
+	MaxEmailLength       = 254 // RFC 5321 path limit
+	MaxDesignationLength = 100
+	MaxBioLength         = 2000
+	MaxURLLength         = 2048
+	MaxTitleLength       = 200
+	MaxDescriptionLength = 5000
+	MaxTimeLength        = 100
+	MaxIDLength          = 128
+)
+
+// ValidationError lists what is wrong with each invalid field, keyed by the
+// field's JSON name.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+// Add records a problem with field, keeping the first one reported.
+func (e *ValidationError) Add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	if _, ok := e.Fields[field]; !ok {
+		e.Fields[field] = message
+	}
+}
+
+// Err returns e if any field was invalid, and nil otherwise.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error joins the field messages in field name order.
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = e.Fields[name]
+	}
+	return strings.Join(messages, "; ")
+}
+
+// line checks a single-line text field: required if asked, at most max
+// characters, valid UTF-8 and free of control characters.
+func (e *ValidationError) line(field, label, value string, max int, required bool) {
+	e.text(field, label, value, max, required, false)
+}
+
+// paragraph is like line but allows tabs and line breaks.
+func (e *ValidationError) paragraph(field, label, value string, max int) {
+	e.text(field, label, value, max, false, true)
+}
+
+func (e *ValidationError) text(field, label, value string, max int, required, multiline bool) {
+	switch {
+	case value == "":
+		if required {
+			e.Add(field, label+" is required")
+		}
+	case !utf8.ValidString(value):
+		e.Add(field, label+" must be valid UTF-8 text")
+	case utf8.RuneCountInString(value) > max:
+		e.Add(field, fmt.Sprintf("%s must be at most %d characters", label, max))
+	case strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsControl(r) && !(multiline && (r == '\n' || r == '\r' || r == '\t'))
+	}) >= 0:
+		e.Add(field, label+" must not contain control characters")
+	}
+}
+
+// email checks a required address in the plain addr-spec form of RFC 5322,
+// e.g. name@example.com; display names and angle brackets are rejected.
+func (e *ValidationError) email(field, value string) {
+	e.line(field, "Email", value, MaxEmailLength, true)
+	if value == "" || e.Fields[field] != "" {
+		return
+	}
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Name != "" || addr.Address != value {
+		e.Add(field, "Email must be a valid address such as name@example.com")
+	}
+}
+
+// url checks an optional absolute http or https URL.
+func (e *ValidationError) url(field, label, value string) {
+	e.line(field, label, value, MaxURLLength, false)
+	if value == "" || e.Fields[field] != "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		e.Add(field, label+" must be an http or https URL")
+	}
+}
+
+// id checks an optional Firestore document ID.
+func (e *ValidationError) id(field, label, value string) {
+	e.line(field, label, value, MaxIDLength, false)
+	if value == "" || e.Fields[field] != "" {
+		return
+	}
+	if strings.Contains(value, "/") || value == "." || value == ".." ||
+		(strings.HasPrefix(value, "__") && strings.HasSuffix(value, "__")) {
+		e.Add(field, label+" is not a valid ID")
+	}
+}
+
+// NormalizeEmail trims surrounding space and lower-cases the domain, which
+// is case-insensitive. The local part is kept as entered.
+func NormalizeEmail(email string) string {
+	email = strings.TrimSpace(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	return email[:at+1] + strings.ToLower(email[at+1:])
+}
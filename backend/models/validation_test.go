@@ -0,0 +1,104 @@
+package models_test
+
+import (
+	"errors"
+	"event-registration-backend/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldErrors(t *testing.T, err error) map[string]string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var invalid *models.ValidationError
+	require.True(t, errors.As(err, &invalid), "want a ValidationError, got %v", err)
+	return invalid.Fields
+}
+
+func TestRegisterRequest_Validate(t *testing.T) {
+	valid := models.RegisterRequest{FullName: "Ada Lovelace", Email: "ada@example.com", Designation: "Engineer"}
+
+	tests := []struct {
+		name   string
+		modify func(r *models.RegisterRequest)
+		want   map[string]string
+	}{
+		{name: "valid", modify: func(r *models.RegisterRequest) {}},
+		{name: "plus address and subdomain", modify: func(r *models.RegisterRequest) { r.Email = "ada+events@mail.example.co.uk" }},
+		{name: "missing everything", modify: func(r *models.RegisterRequest) { *r = models.RegisterRequest{} }, want: map[string]string{
+			"fullName":    "Full name is required",
+			"email":       "Email is required",
+			"designation": "Designation is required",
+		}},
+		{name: "email without domain", modify: func(r *models.RegisterRequest) { r.Email = "ada" },
+			want: map[string]string{"email": "Email must be a valid address such as name@example.com"}},
+		{name: "email with display name", modify: func(r *models.RegisterRequest) { r.Email = "Ada <ada@example.com>" },
+			want: map[string]string{"email": "Email must be a valid address such as name@example.com"}},
+		{name: "email too long", modify: func(r *models.RegisterRequest) { r.Email = strings.Repeat("a", 250) + "@example.com" },
+			want: map[string]string{"email": "Email must be at most 254 characters"}},
+		{name: "name too long", modify: func(r *models.RegisterRequest) { r.FullName = strings.Repeat("é", models.MaxNameLength+1) },
+			want: map[string]string{"fullName": "Full name must be at most 100 characters"}},
+		{name: "name at limit", modify: func(r *models.RegisterRequest) { r.FullName = strings.Repeat("é", models.MaxNameLength) }},
+		{name: "control characters", modify: func(r *models.RegisterRequest) { r.Designation = "Engineer\nInjected: yes" },
+			want: map[string]string{"designation": "Designation must not contain control characters"}},
+		{name: "invalid UTF-8", modify: func(r *models.RegisterRequest) { r.FullName = "Ada \xff" },
+			want: map[string]string{"fullName": "Full name must be valid UTF-8 text"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.modify(&r)
+			assert.Equal(t, tt.want, fieldErrors(t, r.Validate()))
+		})
+	}
+}
+
+func TestRegisterRequest_Normalize(t *testing.T) {
+	r := models.RegisterRequest{FullName: "  Ada Lovelace ", Email: " Ada.L@Example.COM\t", Designation: " Engineer"}
+	r.Normalize()
+
+	assert.Equal(t, models.RegisterRequest{FullName: "Ada Lovelace", Email: "Ada.L@example.com", Designation: "Engineer"}, r)
+	assert.NoError(t, r.Validate())
+}
+
+func TestSpeakerAndSession_Validate(t *testing.T) {
+	assert.NoError(t, models.Speaker{Name: "Grace", Bio: "Line one\nLine two", PhotoURL: "https://example.com/g.png"}.Validate())
+	assert.Equal(t, map[string]string{
+		"id":       "ID is not a valid ID",
+		"photoURL": "Photo URL must be an http or https URL",
+	}, fieldErrors(t, models.Speaker{ID: "a/b", Name: "Grace", PhotoURL: "javascript:alert(1)"}.Validate()))
+
+	assert.NoError(t, models.Session{Title: "Keynote", Time: "09:00", SpeakerID: "grace"}.Validate())
+	assert.Equal(t, map[string]string{
+		"title":     "Title must be at most 200 characters",
+		"speakerId": "Speaker ID is not a valid ID",
+	}, fieldErrors(t, models.Session{Title: strings.Repeat("x", 201), SpeakerID: "__reserved__"}.Validate()))
+}
+
+func TestEmailDomainPolicy(t *testing.T) {
+	p := models.EmailDomainPolicy{
+		Blocked: []string{" Spam.Example ", "@spam.example", ""},
+		Allowed: []string{"acme.com", "ACME.com"},
+	}
+	p.Normalize()
+	assert.Equal(t, []string{"spam.example"}, p.Blocked)
+	assert.Equal(t, []string{"acme.com"}, p.Allowed)
+	assert.NoError(t, p.Validate())
+
+	assert.Equal(t, map[string]string{
+		"blocked": "acme.com is also allowed",
+		"allowed": `"not a domain" is not a domain name`,
+	}, fieldErrors(t, models.EmailDomainPolicy{
+		Blocked: []string{"acme.com"},
+		Allowed: []string{"acme.com", "not a domain"},
+	}.Validate()))
+
+	assert.Equal(t, map[string]string{
+		"allowed": "At least one domain is required when only allowed domains may register",
+	}, fieldErrors(t, models.EmailDomainPolicy{AllowedOnly: true}.Validate()))
+}
@@ -0,0 +1,73 @@
+package openapi
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite openapi.json from Build")
+
+func marshalSpec(t *testing.T) []byte {
+	data, err := json.MarshalIndent(Build(), "", "  ")
+	require.NoError(t, err)
+	return append(data, '\n')
+}
+
+// TestSpecMatchesHandlers fails when a handler's request or response type
+// changes without openapi.json being regenerated. main_test.go checks the
+// spec against the registered routes and the bodies their handlers accept.
+func TestSpecMatchesHandlers(t *testing.T) {
+	generated := marshalSpec(t)
+	if *update {
+		require.NoError(t, os.WriteFile("openapi.json", generated, 0o644))
+		return
+	}
+	assert.JSONEq(t, string(generated), string(specJSON),
+		"openapi.json is out of date with the handler types; run: go test ./openapi -update")
+}
+
+func TestSchemaOf_Struct(t *testing.T) {
+	type Inner struct {
+		A string `json:"a"`
+	}
+	type Outer struct {
+		Inner
+		Name    string         `json:"name"`
+		Note    string         `json:"note,omitempty"`
+		At      *time.Time     `json:"at,omitempty"`
+		Tags    []string       `json:"tags"`
+		Counts  map[string]int `json:"counts"`
+		Any     interface{}    `json:"any"`
+		Skipped string         `json:"-"`
+		hidden  string
+	}
+
+	s := newSchemas()
+	ref := s.of(Outer{})
+	assert.Equal(t, "#/components/schemas/Outer", ref.Ref)
+
+	obj := s.components["Outer"]
+	require.NotNil(t, obj)
+	keys := make([]string, 0, len(obj.Properties))
+	for k := range obj.Properties {
+		keys = append(keys, k)
+	}
+	assert.ElementsMatch(t, []string{"a", "name", "note", "at", "tags", "counts", "any"}, keys)
+	assert.Equal(t, []string{"a", "name", "tags", "counts", "any"}, obj.Required)
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time", Nullable: true}, obj.Properties["at"])
+	assert.Equal(t, "integer", obj.Properties["counts"].AdditionalProperties.Type)
+	assert.Equal(t, &Schema{}, obj.Properties["any"])
+}
+
+func TestSchemaOf_NameCollisionPanics(t *testing.T) {
+	s := newSchemas()
+	s.of(Schema{})
+	type Schema struct{ Other bool }
+	assert.Panics(t, func() { s.of(Schema{}) })
+}
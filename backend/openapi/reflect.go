@@ -0,0 +1,121 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas converts Go types to schemas the way encoding/json would encode
+// them. Named struct types become components referenced by $ref.
+type schemas struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}, types: map[string]reflect.Type{}}
+}
+
+// of returns the schema for v's type.
+func (s *schemas) of(v interface{}) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Ptr:
+		elem := s.schema(t.Elem())
+		if elem.Ref != "" {
+			// OpenAPI 3.0 ignores siblings of $ref, so nullability is
+			// expressed by leaving the property out of required.
+			return elem
+		}
+		elem.Nullable = true
+		return elem
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.ref(t)
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+// ref registers t as a component and returns a reference to it.
+func (s *schemas) ref(t reflect.Type) *Schema {
+	name := t.Name()
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: %s and %s share the schema name %s", existing, t, name))
+		}
+	} else {
+		s.types[name] = t
+		s.components[name] = nil // reserve the name for recursive types
+		s.components[name] = s.object(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// object describes a struct's JSON fields, flattening embedded structs as
+// encoding/json does. Fields without omitempty are required.
+func (s *schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(t, obj)
+	return obj
+}
+
+func (s *schemas) fields(t reflect.Type, obj *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			s.fields(f.Type, obj)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		obj.Properties[name] = s.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package openapi
+
+import (
+	"event-registration-backend/apierror"
+	"event-registration-backend/backup"
+	"event-registration-backend/challenge"
+	"event-registration-backend/events"
+	"event-registration-backend/export"
+	"event-registration-backend/handlers"
+	"event-registration-backend/models"
+	"strings"
+)
+
+// Build describes every /api route from the request and response types its
+// handler uses.
+func Build() *Document {
+	b := &builder{
+		schemas: newSchemas(),
+		doc: &Document{
+			OpenAPI: "3.0.3",
+			Info: Info{
+				Title:       "Event Registration API",
+				Version:     "1.0.0",
+				Description: "Public registration and agenda endpoints, and the admin dashboard API. The unversioned /api prefix is a deprecated alias for /api/v1. Errors have a JSON body with a machine-readable code, a message and, for validation failures, a message per field.",
+			},
+			Paths: map[string]PathItem{},
+		},
+	}
+
+	// Public
+	b.add("GET", "/api/v1/sessions", &Operation{
+		OperationID: "getSessions",
+		Summary:     "List the agenda with each session's speaker",
+		Tags:        []string{"public"},
+		Responses:   responses(b.ok("The agenda", []models.SessionWithSpeaker{}), b.serverError()),
+	})
+	b.add("GET", "/api/v1/speakers", &Operation{
+		OperationID: "getSpeakers",
+		Summary:     "List speakers",
+		Tags:        []string{"public"},
+		Responses:   responses(b.ok("All speakers", []models.Speaker{}), b.serverError()),
+	})
+	b.add("POST", "/api/v1/register", &Operation{
+		OperationID: "registerAttendee",
+		Summary:     "Register an attendee",
+		Description: "Rate limited per client IP. Leave website empty; it is a honeypot. When GET /register/challenge returns a challenge, send its solution as challenge. The registration is pending until confirmed with the link emailed to the attendee, and holds a seat until then. Registering again while pending sends a new link, at most once every ten minutes, and keeps the details and hold of the first registration.",
+		Tags:        []string{"public"},
+		RequestBody: b.jsonBody(models.RegisterRequest{}),
+		Responses: responses(
+			b.json("202", "Pending; a confirmation link was emailed", handlers.RegisterResponse{}),
+			b.fail("400", "validation_failed with per-field details, including an email domain the event does not accept, or invalid_request for a malformed body"),
+			b.fail("403", "challenge_failed: the registration challenge was not solved"),
+			b.fail("409", "email_taken: the email is already registered, or event_full: no seats are left"),
+			b.fail("413", "request_too_large: the body exceeds the size limit"),
+			b.fail("429", "rate_limited: too many registrations from this client, or a link was sent to this email recently; see Retry-After"),
+			b.serverError(),
+		),
+	})
+	b.add("POST", "/api/v1/register/verify", &Operation{
+		OperationID: "verifyRegistration",
+		Summary:     "Confirm a registration with the emailed token",
+		Description: "Confirming an already confirmed registration succeeds.",
+		Tags:        []string{"public"},
+		RequestBody: b.jsonBody(handlers.VerifyRequest{}),
+		Responses: responses(
+			b.ok("Confirmed", handlers.RegisterResponse{}),
+			b.fail("400", "validation_failed: the token is missing"),
+			b.fail("404", "not_found: the token is not valid"),
+			b.fail("410", "verification_expired: the seat hold ended; register again"),
+			b.fail("429", "rate_limited: too many requests from this client; see Retry-After"),
+			b.serverError(),
+		),
+	})
+	b.add("GET", "/api/v1/register/challenge", &Operation{
+		OperationID: "getRegistrationChallenge",
+		Summary:     "The challenge a registration must solve",
+		Description: "type is \"none\" when no challenge is required. For \"proof-of-work\", find a nonce such that SHA-256(\"event-registration:\" + lowercased email + \":\" + nonce) starts with difficulty zero bits, and send the nonce as challenge.",
+		Tags:        []string{"public"},
+		Responses:   responses(b.ok("The current challenge", challenge.Challenge{})),
+	})
+	b.add("GET", "/api/v1/attendees/count", &Operation{
+		OperationID: "getAttendeeCount",
+		Summary:     "Number of confirmed attendees",
+		Tags:        []string{"public"},
+		Responses:   responses(b.ok("The current count", handlers.CountResponse{}), b.serverError()),
+	})
+	b.add("GET", "/api/v1/attendees/count/stream", &Operation{
+		OperationID: "streamAttendeeCount",
+		Summary:     "Live attendee count",
+		Description: "Server-sent events. Each \"count\" event carries a CountResponse as its data; comments are sent as heartbeats.",
+		Tags:        []string{"public"},
+		Responses: responses(&namedResponse{"200", &Response{
+			Description: "An event stream",
+			Content:     map[string]*MediaType{"text/event-stream": {Schema: b.schemas.of(handlers.CountResponse{})}},
+		}}),
+	})
+
+	// Admin
+	b.add("POST", "/api/v1/admin/login", &Operation{
+		OperationID: "adminLogin",
+		Summary:     "Exchange admin credentials for a token",
+		Description: "Named admins send username and password; the shared admin password is sent without a username.",
+		Tags:        []string{"admin"},
+		RequestBody: b.jsonBody(handlers.LoginRequest{}),
+		Responses: responses(
+			b.ok("A token valid for 24 hours", handlers.LoginResponse{}),
+			b.fail("400", "Malformed request"),
+			b.fail("401", "invalid_credentials"),
+			b.fail("413", "request_too_large: the body exceeds the size limit"),
+			b.serverError(),
+		),
+	})
+	b.admin("GET", "/api/v1/admin/attendees", &Operation{
+		OperationID: "getAttendees",
+		Summary:     "List attendees one page at a time",
+		Parameters:  attendeeQueryParams(true),
+		Responses:   responses(b.ok("One page of attendees", models.AttendeePage{}), b.fail("400", "Invalid query parameters"), b.serverError()),
+	})
+	b.admin("GET", "/api/v1/admin/attendees/export", &Operation{
+		OperationID: "exportAttendees",
+		Summary:     "Download matching attendees as CSV or XLSX",
+		Parameters: append(attendeeQueryParams(false),
+			query("format", "csv (default) or xlsx", &Schema{Type: "string", Enum: []string{export.FormatCSV, export.FormatXLSX}}),
+			query("columns", "Comma-separated column keys: "+columnKeys()+" (default all)", &Schema{Type: "string"}),
+			query("tz", "IANA time zone for timestamps (default UTC)", &Schema{Type: "string"}),
+		),
+		Responses: responses(
+			&namedResponse{"200", &Response{
+				Description: "The export file",
+				Content: map[string]*MediaType{
+					export.ContentType(export.FormatCSV):  {Schema: &Schema{Type: "string"}},
+					export.ContentType(export.FormatXLSX): {Schema: &Schema{Type: "string", Format: "binary"}},
+				},
+			}},
+			b.fail("400", "Invalid query parameters"),
+			b.serverError(),
+		),
+	})
+	b.admin("GET", "/api/v1/admin/attendees/search", &Operation{
+		OperationID: "searchAttendees",
+		Summary:     "Search attendees by name, email or designation",
+		Parameters: []Parameter{
+			{Name: "q", In: "query", Required: true, Description: "Search terms; prefixes and small typos match", Schema: &Schema{Type: "string"}},
+			query("limit", "Maximum results", intRange(1, 100, 20)),
+		},
+		Responses: responses(b.ok("Best matches first", models.AttendeePage{}), b.fail("400", "Missing q or invalid limit"), b.serverError()),
+	})
+	b.admin("GET", "/api/v1/admin/stats", &Operation{
+		OperationID: "getStats",
+		Summary:     "Attendee counts by designation",
+		Responses:   responses(b.ok("Designation to count", map[string]int{}), b.serverError()),
+	})
+	b.admin("POST", "/api/v1/admin/speakers", &Operation{
+		OperationID: "saveSpeaker",
+		Summary:     "Create a speaker, or update it when id is set",
+		RequestBody: b.jsonBody(handlers.SpeakerRequest{}),
+		Responses:   responses(b.ok("The saved speaker", models.Speaker{}), b.fail("400", "validation_failed with per-field details"), b.fail("413", "request_too_large: the body exceeds the size limit"), b.serverError()),
+	})
+	b.admin("POST", "/api/v1/admin/sessions", &Operation{
+		OperationID: "saveSession",
+		Summary:     "Create a session, or update it when id is set",
+		RequestBody: b.jsonBody(handlers.SessionRequest{}),
+		Responses:   responses(b.ok("The saved session", models.Session{}), b.fail("400", "validation_failed with per-field details, including a speakerId that does not exist"), b.fail("413", "request_too_large: the body exceeds the size limit"), b.serverError()),
+	})
+	b.admin("POST", "/api/v1/admin/check-in", &Operation{
+		OperationID: "checkInAttendee",
+		Summary:     "Check in a registered attendee by id or email",
+		Description: "Checking in twice succeeds and keeps the first time.",
+		RequestBody: b.jsonBody(handlers.CheckInRequest{}),
+		Responses: responses(
+			b.ok("The checked-in attendee", models.Attendee{}),
+			b.fail("400", "invalid_request: exactly one of id or email is required"),
+			b.fail("404", "not_found: no attendee has this id or email"),
+			b.fail("409", "conflict: the attendee has not confirmed their registration"),
+			b.fail("413", "request_too_large: the body exceeds the size limit"),
+			b.serverError(),
+		),
+	})
+	for _, kind := range []string{"attendees", "speakers", "sessions"} {
+		b.admin("POST", "/api/v1/admin/import/"+kind, &Operation{
+			OperationID: "import" + strings.ToUpper(kind[:1]) + kind[1:],
+			Summary:     "Bulk import " + kind + " from CSV",
+			Description: "Nothing is written if any row is invalid.",
+			Parameters:  []Parameter{query("dryRun", "Validate and preview without writing", &Schema{Type: "boolean"})},
+			RequestBody: csvUpload(),
+			Responses: responses(
+				b.ok("Import or dry-run result", handlers.ImportResult{}),
+				b.fail("400", "Unreadable upload or CSV"),
+				b.fail("413", "request_too_large: the upload exceeds the size limit"),
+				b.fail("422", "Some rows are invalid, such as sessions naming a speaker that does not exist; fields are keyed \"row N\" and nothing was written"),
+				b.json("500", "Storage or internal error. When storage failed after some rows were written the code is import_incomplete and imported counts them from the top of the file; import only the remaining rows", handlers.ImportIncomplete{}),
+			),
+		})
+	}
+	b.admin("GET", "/api/v1/admin/email-domains", &Operation{
+		OperationID: "getEmailDomains",
+		Summary:     "The email domain policy for registrations",
+		Responses:   responses(b.ok("The current policy", models.EmailDomainPolicy{}), b.serverError()),
+	})
+	b.admin("PUT", "/api/v1/admin/email-domains", &Operation{
+		OperationID: "updateEmailDomains",
+		Summary:     "Replace the email domain policy",
+		Description: "A listed domain also covers its subdomains. Where the lists overlap the most specific entry wins, so blocking bad.example.com takes effect even when example.com is allowed. Allowed domains are exempt from the bundled disposable-domain list; with allowedOnly, only they may register.",
+		RequestBody: b.jsonBody(handlers.EmailDomainsRequest{}),
+		Responses: responses(
+			b.ok("The saved policy", models.EmailDomainPolicy{}),
+			b.fail("400", "validation_failed with per-field details"),
+			b.fail("413", "request_too_large: the body exceeds the size limit"),
+			b.serverError(),
+		),
+	})
+	b.admin("GET", "/api/v1/admin/backup", &Operation{
+		OperationID: "backupEvent",
+		Summary:     "Download the event as a JSON archive",
+		Responses:   responses(b.ok("The archive", backup.Archive{}), b.serverError()),
+	})
+	b.admin("POST", "/api/v1/admin/restore", &Operation{
+		OperationID: "restoreEvent",
+		Summary:     "Restore an archive into empty collections",
+		Description: "The migrations ledger need not be empty; it is replaced by the archive's, so migrations the archived documents missed are pending afterwards. Collections are written in batches, not atomically; a restore that fails partway keeps what it wrote and is finished by sending the same archive with resume=true.",
+		Parameters: []Parameter{
+			query("collections", "Comma-separated collections to restore: "+strings.Join(backup.AllCollections, ", ")+" (default all)", &Schema{Type: "string"}),
+			query("resume", "Finish a failed restore of the same archive: target collections may hold its documents but nothing else", &Schema{Type: "boolean"}),
+		},
+		RequestBody: b.jsonBody(backup.Archive{}),
+		Responses: responses(
+			b.ok("Documents restored per collection", map[string]int{}),
+			b.fail("400", "Invalid archive or unknown collection"),
+			b.fail("409", "A target collection is not empty, or when resuming has documents that are not in the archive"),
+			b.json("500", "Storage or internal error. When storage failed after some documents were written the code is restore_incomplete and restored counts them per collection; resume to finish", handlers.RestoreIncomplete{}),
+		),
+	})
+	b.add("GET", "/api/v1/admin/feed", &Operation{
+		OperationID: "adminFeed",
+		Summary:     "Live roster and agenda events",
+		Description: "WebSocket. Each message is an Event encoded as JSON. Browsers cannot set headers on WebSocket requests, so the token may be passed as a query parameter.",
+		Tags:        []string{"admin"},
+		Parameters:  []Parameter{query("token", "Admin token, if no Authorization header is sent", &Schema{Type: "string"})},
+		Responses: responses(
+			&namedResponse{"101", &Response{
+				Description: "Switching to the WebSocket protocol",
+				Content:     map[string]*MediaType{"application/json": {Schema: b.schemas.of(events.Event{})}},
+			}},
+			b.fail("401", "Missing, invalid or expired token"),
+		),
+	})
+
+	b.add("GET", "/api/v1/openapi.json", &Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "This document",
+		Tags:        []string{"meta"},
+		Responses:   responses(&namedResponse{"200", &Response{Description: "OpenAPI 3 document", Content: map[string]*MediaType{"application/json": {Schema: &Schema{Type: "object"}}}}}),
+	})
+
+	b.doc.Components = Components{
+		Schemas: b.schemas.components,
+		SecuritySchemes: map[string]*SecurityScheme{
+			"adminToken": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	}
+	return b.doc
+}
+
+type builder struct {
+	doc     *Document
+	schemas *schemas
+}
+
+func (b *builder) add(method, path string, op *Operation) {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[path] = item
+	}
+	// Anything that can fail in storage can also find it slow or unavailable
+	if _, ok := op.Responses["500"]; ok {
+		op.Responses["503"] = b.fail("503", "unavailable: storage is temporarily unavailable; see Retry-After").response
+		op.Responses["504"] = b.fail("504", "timeout: storage did not answer in time").response
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// admin adds an operation behind AdminAuthMiddleware.
+func (b *builder) admin(method, path string, op *Operation) {
+	op.Tags = []string{"admin"}
+	op.Security = []map[string][]string{{"adminToken": {}}}
+	op.Responses["401"] = b.fail("401", "Missing or invalid token").response
+	b.add(method, path, op)
+}
+
+func (b *builder) jsonBody(v interface{}) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: b.schemas.of(v)}}}
+}
+
+func (b *builder) json(status, description string, v interface{}) *namedResponse {
+	return &namedResponse{status, &Response{
+		Description: description,
+		Content:     map[string]*MediaType{"application/json": {Schema: b.schemas.of(v)}},
+	}}
+}
+
+func (b *builder) ok(description string, v interface{}) *namedResponse {
+	return b.json("200", description, v)
+}
+
+type namedResponse struct {
+	status   string
+	response *Response
+}
+
+func responses(rs ...*namedResponse) map[string]*Response {
+	m := make(map[string]*Response, len(rs))
+	for _, r := range rs {
+		m[r.status] = r.response
+	}
+	return m
+}
+
+// fail describes an error response, which always has an apierror.Envelope
+// body.
+func (b *builder) fail(status, description string) *namedResponse {
+	return b.json(status, description, apierror.Envelope{})
+}
+
+func (b *builder) serverError() *namedResponse {
+	return b.fail("500", "Storage or internal error")
+}
+
+func query(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func intRange(min, max, def int) *Schema {
+	return &Schema{Type: "integer", Minimum: &min, Maximum: &max, Default: def}
+}
+
+func csvUpload() *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			"text/csv": {Schema: &Schema{Type: "string"}},
+			"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"file": {Type: "string", Format: "binary"}},
+				Required:   []string{"file"},
+			}},
+		},
+	}
+}
+
+// attendeeQueryParams lists the filters shared by the attendee list and
+// export; paging only applies to the list.
+func attendeeQueryParams(paged bool) []Parameter {
+	params := []Parameter{
+		query("sort", "Sort field", &Schema{Type: "string", Enum: []string{"createdAt", "fullName"}, Default: "createdAt"}),
+		query("order", "Default desc for createdAt, asc for fullName", &Schema{Type: "string", Enum: []string{"asc", "desc"}}),
+		query("designation", "Exact designation match", &Schema{Type: "string"}),
+		query("status", "Exact status match", &Schema{Type: "string"}),
+		query("from", "Registered at or after; RFC 3339 or YYYY-MM-DD. Requires sort=createdAt", &Schema{Type: "string"}),
+		query("to", "Registered before; RFC 3339, or YYYY-MM-DD to include that whole day. Requires sort=createdAt", &Schema{Type: "string"}),
+	}
+	if paged {
+		params = append(params,
+			query("limit", "Page size", intRange(1, 500, 50)),
+			query("cursor", "nextCursor from the previous page", &Schema{Type: "string"}),
+		)
+	}
+	return params
+}
+
+func columnKeys() string {
+	keys := make([]string, len(export.AttendeeColumns))
+	for i, c := range export.AttendeeColumns {
+		keys[i] = c.Key
+	}
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,47 @@
+// Package requestid carries a per-request correlation ID through contexts
+// so log lines and error responses for the same request can be matched.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the request and response header that carries the ID.
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// New returns a random 16-byte ID as 32 hex characters.
+func New() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the ID stored in ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Valid reports whether an ID supplied by a client or proxy is safe to
+// reuse: 1-64 letters, digits, '-', '_' or '.'.
+func Valid(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
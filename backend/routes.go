@@ -0,0 +1,90 @@
+package main
+
+import (
+	"event-registration-backend/config"
+	"event-registration-backend/handlers"
+	"event-registration-backend/middleware"
+	"event-registration-backend/openapi"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// The unversioned /api prefix predates versioning and serves v1 until
+// legacyAPISunset. Clients should move to /api/v1.
+var (
+	legacyAPIDeprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacyAPISunset     = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// registerAPIRoutes mounts each API version under its own /api/vN prefix.
+// A breaking change goes into a new version with its own register function
+// and subrouter, leaving existing versions as they are. Versioned prefixes
+// must be mounted before the legacy /api alias.
+func registerAPIRoutes(r *mux.Router, cfg *config.Config) error {
+	trusted, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	// One limiter for every version, so switching prefixes buys nothing
+	limits := apiLimits{
+		register: middleware.NewRateLimiter(cfg.RegisterRateLimit, cfg.RegisterRateBurst, trusted),
+	}
+
+	registerV1(r.PathPrefix("/api/v1").Subrouter(), limits)
+
+	legacy := r.PathPrefix("/api").Subrouter()
+	legacy.Use(middleware.Deprecated(legacyAPIDeprecated, legacyAPISunset, func(path string) string {
+		return "/api/v1" + strings.TrimPrefix(path, "/api")
+	}))
+	registerV1(legacy, limits)
+	return nil
+}
+
+// registerHealthRoutes adds the liveness and readiness probes. They sit
+// outside /api so they are not versioned, rate limited or deprecated.
+func registerHealthRoutes(r *mux.Router) {
+	r.HandleFunc("/healthz", handlers.Healthz).Methods("GET", "HEAD")
+	r.HandleFunc("/readyz", handlers.Readyz).Methods("GET", "HEAD")
+}
+
+// apiLimits holds the rate limiters shared by all API versions.
+type apiLimits struct {
+	register *middleware.RateLimiter
+}
+
+// registerV1 adds the v1 routes to r. Each route must also be described in
+// the OpenAPI spec; main_test.go checks that they match.
+func registerV1(r *mux.Router, limits apiLimits) {
+	// Public API routes
+	r.HandleFunc("/sessions", handlers.GetSessions).Methods("GET")
+	r.HandleFunc("/speakers", handlers.GetSpeakers).Methods("GET")
+	r.Handle("/register", limits.register.Limit(http.HandlerFunc(handlers.RegisterAttendee))).Methods("POST")
+	r.HandleFunc("/register/challenge", handlers.GetRegistrationChallenge).Methods("GET")
+	r.Handle("/register/verify", limits.register.Limit(http.HandlerFunc(handlers.VerifyRegistration))).Methods("POST")
+	r.HandleFunc("/attendees/count", handlers.GetAttendeeCount).Methods("GET")
+	r.HandleFunc("/attendees/count/stream", handlers.StreamAttendeeCount).Methods("GET")
+
+	// Admin routes
+	r.HandleFunc("/admin/login", handlers.AdminLogin).Methods("POST")
+	r.HandleFunc("/admin/attendees", handlers.AdminAuthMiddleware(handlers.GetAttendees)).Methods("GET")
+	r.HandleFunc("/admin/attendees/export", handlers.AdminAuthMiddleware(handlers.ExportAttendees)).Methods("GET")
+	r.HandleFunc("/admin/attendees/search", handlers.AdminAuthMiddleware(handlers.SearchAttendees)).Methods("GET")
+	r.HandleFunc("/admin/stats", handlers.AdminAuthMiddleware(handlers.GetStats)).Methods("GET")
+	r.HandleFunc("/admin/speakers", handlers.AdminAuthMiddleware(handlers.AddUpdateSpeaker)).Methods("POST")
+	r.HandleFunc("/admin/sessions", handlers.AdminAuthMiddleware(handlers.AddUpdateSession)).Methods("POST")
+	r.HandleFunc("/admin/check-in", handlers.AdminAuthMiddleware(handlers.CheckInAttendee)).Methods("POST")
+	r.HandleFunc("/admin/import/attendees", handlers.AdminAuthMiddleware(handlers.ImportAttendees)).Methods("POST")
+	r.HandleFunc("/admin/import/speakers", handlers.AdminAuthMiddleware(handlers.ImportSpeakers)).Methods("POST")
+	r.HandleFunc("/admin/import/sessions", handlers.AdminAuthMiddleware(handlers.ImportSessions)).Methods("POST")
+	r.HandleFunc("/admin/email-domains", handlers.AdminAuthMiddleware(handlers.GetEmailDomains)).Methods("GET")
+	r.HandleFunc("/admin/email-domains", handlers.AdminAuthMiddleware(handlers.UpdateEmailDomains)).Methods("PUT")
+	r.HandleFunc("/admin/backup", handlers.AdminAuthMiddleware(handlers.BackupEvent)).Methods("GET")
+	r.HandleFunc("/admin/restore", handlers.AdminAuthMiddleware(handlers.RestoreEvent)).Methods("POST")
+	r.HandleFunc("/admin/feed", handlers.AdminFeed).Methods("GET")
+
+	// API description
+	r.HandleFunc("/openapi.json", openapi.ServeSpec).Methods("GET")
+}
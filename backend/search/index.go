@@ -0,0 +1,238 @@
+package search
+
+import (
+	"event-registration-backend/models"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Match scores, highest first. A document's score is the sum of the best
+// match for each query term.
+const (
+	scoreExact  = 3
+	scorePrefix = 2
+	scoreFuzzy  = 1
+)
+
+// Index is an in-memory inverted index over attendee name, email and
+// designation supporting prefix and typo-tolerant lookups. It is safe for
+// concurrent use.
+type Index struct {
+	mu        sync.RWMutex
+	attendees map[string]models.Attendee
+	postings  map[string]map[string]struct{}
+}
+
+func NewIndex() *Index {
+	return &Index{
+		attendees: make(map[string]models.Attendee),
+		postings:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Add indexes an attendee, replacing any previous version with the same ID.
+func (idx *Index) Add(a models.Attendee) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(a.ID)
+	idx.attendees[a.ID] = a
+	for _, term := range attendeeTerms(a) {
+		ids, ok := idx.postings[term]
+		if !ok {
+			ids = make(map[string]struct{})
+			idx.postings[term] = ids
+		}
+		ids[a.ID] = struct{}{}
+	}
+}
+
+// Remove drops an attendee from the index.
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id string) {
+	a, ok := idx.attendees[id]
+	if !ok {
+		return
+	}
+	delete(idx.attendees, id)
+	for _, term := range attendeeTerms(a) {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+}
+
+// Replace swaps the whole index contents for attendees.
+func (idx *Index) Replace(attendees []models.Attendee) {
+	fresh := NewIndex()
+	for _, a := range attendees {
+		fresh.Add(a)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.attendees = fresh.attendees
+	idx.postings = fresh.postings
+}
+
+// Len returns the number of indexed attendees.
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.attendees)
+}
+
+// Search returns up to limit attendees matching every term of query,
+// best matches first. Each term matches an indexed word exactly, as a
+// prefix, or within a small edit distance.
+func (idx *Index) Search(query string, limit int) []models.Attendee {
+	terms := tokenize(query)
+	if len(terms) == 0 || limit <= 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var scores map[string]int
+	for _, term := range terms {
+		termScores := idx.match(term)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if s, ok := termScores[id]; ok {
+				scores[id] = score + s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	results := make([]models.Attendee, 0, len(scores))
+	for id := range scores {
+		results = append(results, idx.attendees[id])
+	}
+	sort.Slice(results, func(i, j int) bool {
+		si, sj := scores[results[i].ID], scores[results[j].ID]
+		if si != sj {
+			return si > sj
+		}
+		if results[i].FullName != results[j].FullName {
+			return results[i].FullName < results[j].FullName
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// match returns the best score of term against each attendee.
+func (idx *Index) match(term string) map[string]int {
+	maxDistance := fuzzyDistance(term)
+	scores := make(map[string]int)
+	for word, ids := range idx.postings {
+		score := 0
+		switch {
+		case word == term:
+			score = scoreExact
+		case strings.HasPrefix(word, term):
+			score = scorePrefix
+		case maxDistance > 0 && withinDistance(term, word, maxDistance):
+			score = scoreFuzzy
+		default:
+			continue
+		}
+		for id := range ids {
+			if score > scores[id] {
+				scores[id] = score
+			}
+		}
+	}
+	return scores
+}
+
+// fuzzyDistance is how many edits a query term may be from an indexed word.
+// Short terms must match exactly or as a prefix to avoid noisy results.
+func fuzzyDistance(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// attendeeTerms lists the words an attendee is indexed under. Emails are
+// indexed both whole and split into their parts so "jane@acme.com",
+// "jane" and "acme" all match.
+func attendeeTerms(a models.Attendee) []string {
+	terms := tokenize(a.FullName + " " + a.Designation + " " + a.Email)
+	if email := strings.ToLower(strings.TrimSpace(a.Email)); email != "" {
+		terms = append(terms, email)
+	}
+
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// tokenize lowercases s and splits it on anything that is not a letter or
+// digit.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// withinDistance reports whether the Levenshtein distance between a and b
+// is at most maxDist, giving up early once every path exceeds it.
+func withinDistance(a, b string, maxDist int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > maxDist || -d > maxDist {
+		return false
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > maxDist {
+			return false
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)] <= maxDist
+}
@@ -0,0 +1,79 @@
+package search_test
+
+import (
+	"event-registration-backend/models"
+	"event-registration-backend/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func names(attendees []models.Attendee) []string {
+	var out []string
+	for _, a := range attendees {
+		out = append(out, a.FullName)
+	}
+	return out
+}
+
+func newTestIndex() *search.Index {
+	idx := search.NewIndex()
+	idx.Add(models.Attendee{ID: "1", FullName: "Jane Doe", Email: "jane@acme.com", Designation: "Software Engineer"})
+	idx.Add(models.Attendee{ID: "2", FullName: "John Smith", Email: "jsmith@example.com", Designation: "Product Manager"})
+	idx.Add(models.Attendee{ID: "3", FullName: "Janet Müller", Email: "janet@example.org", Designation: "Designer"})
+	return idx
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := newTestIndex()
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "Exact ranks above prefix", query: "jane", want: []string{"Jane Doe", "Janet Müller"}},
+		{name: "Prefix", query: "smi", want: []string{"John Smith"}},
+		{name: "Fuzzy", query: "smyth", want: []string{"John Smith"}},
+		{name: "Email", query: "jsmith@example.com", want: []string{"John Smith"}},
+		{name: "Email domain", query: "acme", want: []string{"Jane Doe"}},
+		{name: "Designation", query: "product", want: []string{"John Smith"}},
+		{name: "All terms must match", query: "jane engineer", want: []string{"Jane Doe"}},
+		{name: "Unicode names", query: "müller", want: []string{"Janet Müller"}},
+		{name: "Case insensitive", query: "JOHN", want: []string{"John Smith"}},
+		{name: "No match", query: "zebra", want: nil},
+		{name: "Empty query", query: "  ", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, names(idx.Search(tt.query, 10)))
+		})
+	}
+}
+
+func TestIndex_Limit(t *testing.T) {
+	idx := newTestIndex()
+	assert.Len(t, idx.Search("jan", 1), 1)
+}
+
+func TestIndex_UpdateAndRemove(t *testing.T) {
+	idx := newTestIndex()
+
+	idx.Add(models.Attendee{ID: "2", FullName: "John Carter", Email: "jcarter@example.com", Designation: "Product Manager"})
+	assert.Empty(t, idx.Search("smith", 10), "old terms are dropped on update")
+	assert.Equal(t, []string{"John Carter"}, names(idx.Search("carter", 10)))
+
+	idx.Remove("2")
+	assert.Empty(t, idx.Search("carter", 10))
+	assert.Equal(t, 2, idx.Len())
+}
+
+func TestIndex_Replace(t *testing.T) {
+	idx := newTestIndex()
+	idx.Replace([]models.Attendee{{ID: "9", FullName: "Ada Lovelace"}})
+
+	assert.Equal(t, 1, idx.Len())
+	assert.Empty(t, idx.Search("jane", 10))
+	assert.Equal(t, []string{"Ada Lovelace"}, names(idx.Search("ada", 10)))
+}
@@ -0,0 +1,23 @@
+//go:build embedfrontend
+
+package static
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist is the frontend build, copied here before building with
+// -tags embedfrontend (see the Makefile's build-embedded target).
+//
+//go:embed dist
+var dist embed.FS
+
+// Embedded returns the frontend compiled into the binary.
+func Embedded() (fs.FS, bool) {
+	site, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil, false
+	}
+	return site, true
+}
@@ -0,0 +1,11 @@
+//go:build !embedfrontend
+
+package static
+
+import "io/fs"
+
+// Embedded reports false: this binary was built without -tags
+// embedfrontend and serves the frontend from disk, if at all.
+func Embedded() (fs.FS, bool) {
+	return nil, false
+}
@@ -0,0 +1,174 @@
+// Package static serves the built frontend as a single-page app.
+package static
+
+import (
+	"errors"
+	"event-registration-backend/apierror"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// indexFile is served for client-side routes such as /verify.
+const indexFile = "index.html"
+
+// assetsDir holds the bundler's output, whose file names carry a content
+// hash and so never change content.
+const assetsDir = "assets/"
+
+const (
+	cacheImmutable = "public, max-age=31536000, immutable"
+	// The page must be revalidated so a new deploy's asset names are picked up
+	cacheRevalidate = "no-cache"
+)
+
+// encodings are the precompressed variants looked for next to each file,
+// in order of preference.
+var encodings = []struct {
+	name string
+	ext  string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Handler serves the regular files in fsys and falls back to index.html for
+// paths that look like client-side routes. Request paths are cleaned and
+// checked with fs.ValidPath before use, and fs.FS implementations refuse
+// names that leave their root, so no request can read outside fsys.
+// Directories are never listed.
+//
+// When the client accepts it, a file's precompressed sibling, such as
+// app.js.br or app.js.gz, is sent in its place.
+func Handler(fsys fs.FS) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			apierror.MethodNotAllowed(w, r)
+			return
+		}
+
+		name, ok := fileName(r.URL.Path)
+		if !ok {
+			apierror.NotFound(w, r)
+			return
+		}
+
+		if name != "" && isFile(fsys, name) {
+			serveFile(w, r, fsys, name)
+			return
+		}
+
+		// A missing file with an extension is a broken asset link, not a
+		// page; answering with index.html would hide the error
+		if path.Ext(name) != "" {
+			apierror.NotFound(w, r)
+			return
+		}
+		serveFile(w, r, fsys, indexFile)
+	})
+}
+
+// Dir returns the frontend build in dir on disk. It reports false unless
+// dir holds an index.html, so a directory of anything else, such as this
+// package's own source, is never served as the site.
+func Dir(dir string) (fs.FS, bool) {
+	site := os.DirFS(dir)
+	if !isFile(site, indexFile) {
+		return nil, false
+	}
+	return site, true
+}
+
+// fileName maps a URL path to a name in the served file system. It
+// reports false for paths no file could have: ones with backslashes or NUL
+// bytes, which some file systems treat as separators or terminators.
+func fileName(urlPath string) (string, bool) {
+	if strings.ContainsAny(urlPath, "\\\x00") {
+		return "", false
+	}
+	// Cleaning a rooted path resolves every ".." without escaping the root
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" {
+		return "", true
+	}
+	return name, fs.ValidPath(name)
+}
+
+func isFile(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// serveFile sends name, or its best precompressed variant the client
+// accepts, with caching headers for its kind of file.
+func serveFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) {
+	h := w.Header()
+	switch {
+	case name == indexFile:
+		h.Set("Cache-Control", cacheRevalidate)
+	case strings.HasPrefix(name, assetsDir):
+		h.Set("Cache-Control", cacheImmutable)
+	}
+
+	served := name
+	for _, enc := range encodings {
+		if isFile(fsys, name+enc.ext) {
+			// The response depends on Accept-Encoding whenever a variant exists
+			h.Add("Vary", "Accept-Encoding")
+			break
+		}
+	}
+	for _, enc := range encodings {
+		if acceptsEncoding(r.Header.Get("Accept-Encoding"), enc.name) && isFile(fsys, name+enc.ext) {
+			served = name + enc.ext
+			h.Set("Content-Encoding", enc.name)
+			break
+		}
+	}
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		h.Set("Content-Type", ctype)
+	}
+
+	f, err := fsys.Open(served)
+	if err != nil {
+		apierror.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		apierror.Internal(w, r, "Failed to read "+served, err)
+		return
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		apierror.Internal(w, r, "Failed to read "+served, errors.New("file is not seekable"))
+		return
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header value accepts
+// coding, honouring "q=0" refusals.
+func acceptsEncoding(header, coding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		enc, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(enc), coding) {
+			continue
+		}
+		q := strings.TrimSpace(params)
+		if v, ok := strings.CutPrefix(q, "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil && f == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
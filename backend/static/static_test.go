@@ -0,0 +1,167 @@
+package static_test
+
+import (
+	"event-registration-backend/static"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSite = fstest.MapFS{
+	"index.html":         {Data: []byte("<html>app</html>")},
+	"vite.svg":           {Data: []byte("<svg/>")},
+	"assets/index-1.js":  {Data: []byte("console.log(1)")},
+	"assets/index-1.css": {Data: []byte("body{}")},
+}
+
+func get(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	h := static.Handler(testSite)
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		body   string
+	}{
+		{"index", "/", http.StatusOK, "<html>app</html>"},
+		{"asset", "/assets/index-1.js", http.StatusOK, "console.log(1)"},
+		{"top-level file", "/vite.svg", http.StatusOK, "<svg/>"},
+		{"client route", "/verify", http.StatusOK, "<html>app</html>"},
+		{"nested client route", "/admin/attendees", http.StatusOK, "<html>app</html>"},
+		{"index by name", "/index.html", http.StatusOK, "<html>app</html>"},
+		{"directory is not listed", "/assets/", http.StatusOK, "<html>app</html>"},
+		{"missing asset", "/assets/index-2.js", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(t, h, tt.target)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_IndexNotCached(t *testing.T) {
+	w := get(t, static.Handler(testSite), "/")
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+}
+
+func TestHandler_CacheHeaders(t *testing.T) {
+	h := static.Handler(testSite)
+	assert.Equal(t, "public, max-age=31536000, immutable", get(t, h, "/assets/index-1.js").Header().Get("Cache-Control"))
+	assert.Equal(t, "no-cache", get(t, h, "/index.html").Header().Get("Cache-Control"))
+	assert.Equal(t, "no-cache", get(t, h, "/verify").Header().Get("Cache-Control"))
+	assert.Empty(t, get(t, h, "/vite.svg").Header().Get("Cache-Control"))
+}
+
+func TestHandler_Precompressed(t *testing.T) {
+	h := static.Handler(fstest.MapFS{
+		"index.html":           {Data: []byte("<html>app</html>")},
+		"index.html.gz":        {Data: []byte("gzipped index")},
+		"assets/index-1.js":    {Data: []byte("console.log(1)")},
+		"assets/index-1.js.gz": {Data: []byte("gzipped js")},
+		"assets/index-1.js.br": {Data: []byte("brotli js")},
+	})
+
+	tests := []struct {
+		name           string
+		target         string
+		acceptEncoding string
+		encoding       string
+		body           string
+	}{
+		{"prefers brotli", "/assets/index-1.js", "gzip, deflate, br", "br", "brotli js"},
+		{"gzip only", "/assets/index-1.js", "gzip", "gzip", "gzipped js"},
+		{"brotli refused", "/assets/index-1.js", "br;q=0, gzip;q=0.8", "gzip", "gzipped js"},
+		{"identity", "/assets/index-1.js", "", "", "console.log(1)"},
+		{"index fallback", "/verify", "gzip", "gzip", "gzipped index"},
+		{"no brotli variant", "/", "br", "", "<html>app</html>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.encoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, tt.body, w.Body.String())
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		})
+	}
+
+	// Compression must not change the declared type
+	req := httptest.NewRequest("GET", "/assets/index-1.js", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+}
+
+func TestHandler_RejectsWrites(t *testing.T) {
+	w := httptest.NewRecorder()
+	static.Handler(testSite).ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, HEAD", w.Header().Get("Allow"))
+}
+
+// TestHandler_StaysInRoot serves a real directory next to a secret file and
+// checks that no path reaches the secret.
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "static.go"), []byte("package static"), 0o644))
+	_, ok := static.Dir(dir)
+	assert.False(t, ok, "a directory without index.html is not a frontend build")
+	_, ok = static.Dir(filepath.Join(dir, "missing"))
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>app</html>"), 0o644))
+	site, ok := static.Dir(dir)
+	require.True(t, ok)
+	w := get(t, static.Handler(site), "/")
+	assert.Equal(t, "<html>app</html>", w.Body.String())
+}
+
+func TestHandler_StaysInRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "static")
+	require.NoError(t, os.Mkdir(root, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "index.html"), []byte("<html>app</html>"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("top secret"), 0o644))
+	h := static.Handler(os.DirFS(root))
+
+	for _, target := range []string{
+		"/../secret.txt",
+		"/assets/../../secret.txt",
+		"/%2e%2e/secret.txt",
+		"/%2e%2e%2fsecret.txt",
+		"/..%5csecret.txt",
+		"/%5c..%5csecret.txt",
+		"/secret.txt%00.html",
+		"//../secret.txt",
+	} {
+		t.Run(target, func(t *testing.T) {
+			w := get(t, h, target)
+			assert.NotContains(t, w.Body.String(), "top secret")
+		})
+	}
+}
@@ -0,0 +1,15 @@
+#!/bin/sh
+# Writes .gz and, when brotli is installed, .br copies of the text files in a
+# frontend build, for the backend to serve to clients that accept them.
+# Usage: scripts/precompress.sh [dist]
+set -eu
+
+dir=${1:-dist}
+
+find "$dir" -type f \( -name '*.html' -o -name '*.js' -o -name '*.css' -o -name '*.svg' -o -name '*.json' \) |
+while read -r file; do
+	gzip -9 -c "$file" > "$file.gz"
+	if command -v brotli > /dev/null; then
+		brotli -q 11 -f -o "$file.br" "$file"
+	fi
+done
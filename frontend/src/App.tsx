@@ -1,16 +1,22 @@
 import { useState, useEffect } from 'react';
-import { BrowserRouter as Router, Routes, Route, useNavigate } from 'react-router-dom';
+import { BrowserRouter as Router, Routes, Route, useLocation, useNavigate } from 'react-router-dom';
 import SessionsSpeakers from './components/SessionsSpeakers';
 import Registration from './components/Registration';
 import Location from './components/Location';
 import AdminLogin from './components/AdminLogin';
 import AdminDashboard from './components/AdminDashboard';
+import VerifyRegistration from './components/VerifyRegistration';
 import './styles/App.css';
 
 function AppContent() {
   const [showAdminLogin, setShowAdminLogin] = useState(false);
   const [isAdmin, setIsAdmin] = useState(!!localStorage.getItem('adminToken'));
   const navigate = useNavigate();
+  const location = useLocation();
+  // Confirmation links from registration emails open /verify?token=...
+  const verifyToken = location.pathname === '/verify'
+    ? new URLSearchParams(location.search).get('token')
+    : null;
 
   useEffect(() => {
     if (isAdmin && window.location.pathname !== '/admin') {
@@ -51,6 +57,10 @@ function AppContent() {
         </a>
       </footer>
 
+      {verifyToken && (
+        <VerifyRegistration token={verifyToken} onClose={() => navigate('/')} />
+      )}
+
       {showAdminLogin && !isAdmin && (
         <AdminLogin
           onSuccess={handleAdminLoginSuccess}
@@ -1,6 +1,6 @@
 import { useState, useEffect } from 'react';
 import { PieChart, Pie, Cell, ResponsiveContainer, Legend, Tooltip } from 'recharts';
-import { getAttendees, getStats, getSpeakers, getSessions, addUpdateSpeaker, addUpdateSession } from '../services/api';
+import { getAttendees, getStats, getSpeakers, getSessions, addUpdateSpeaker, addUpdateSession, subscribeAdminFeed, searchAttendees, exportAttendees } from '../services/api';
 import type { Attendee, Speaker, SessionWithSpeaker, Stats } from '../types';
 import './AdminDashboard.css';
 
@@ -12,11 +12,13 @@ const COLORS = ['#0088FE', '#00C49F', '#FFBB28', '#FF8042', '#8884D8', '#82CA9D'
 
 const AdminDashboard: React.FC<AdminDashboardProps> = ({ onLogout }) => {
   const [attendees, setAttendees] = useState<Attendee[]>([]);
+  const [nextCursor, setNextCursor] = useState<string | undefined>();
   const [stats, setStats] = useState<Stats>({});
   const [speakers, setSpeakers] = useState<Speaker[]>([]);
   const [sessions, setSessions] = useState<SessionWithSpeaker[]>([]);
   const [activeTab, setActiveTab] = useState<'attendees' | 'speakers' | 'sessions'>('attendees');
   const [searchTerm, setSearchTerm] = useState('');
+  const [searchResults, setSearchResults] = useState<Attendee[]>([]);
 
   // Speaker form state
   const [speakerForm, setSpeakerForm] = useState<Partial<Speaker>>({ name: '', bio: '', photoURL: '' });
@@ -30,15 +32,19 @@ const AdminDashboard: React.FC<AdminDashboardProps> = ({ onLogout }) => {
     loadData();
   }, []);
 
+  // Refresh whenever the backend reports a registration or agenda change
+  useEffect(() => subscribeAdminFeed(() => loadData()), []);
+
   const loadData = async () => {
     try {
-      const [attendeesData, statsData, speakersData, sessionsData] = await Promise.all([
+      const [attendeesPage, statsData, speakersData, sessionsData] = await Promise.all([
         getAttendees(),
         getStats(),
         getSpeakers(),
         getSessions(),
       ]);
-      setAttendees(attendeesData);
+      setAttendees(attendeesPage.attendees);
+      setNextCursor(attendeesPage.nextCursor);
       setStats(statsData);
       setSpeakers(speakersData);
       setSessions(sessionsData);
@@ -47,6 +53,17 @@ const AdminDashboard: React.FC<AdminDashboardProps> = ({ onLogout }) => {
     }
   };
 
+  const loadMoreAttendees = async () => {
+    if (!nextCursor) return;
+    try {
+      const page = await getAttendees({ cursor: nextCursor });
+      setAttendees((current) => [...current, ...page.attendees]);
+      setNextCursor(page.nextCursor);
+    } catch (error) {
+      console.error('Failed to load more attendees:', error);
+    }
+  };
+
   const handleLogout = () => {
     localStorage.removeItem('adminToken');
     onLogout();
@@ -83,11 +100,24 @@ const AdminDashboard: React.FC<AdminDashboardProps> = ({ onLogout }) => {
     }
   };
 
-  const filteredAttendees = attendees.filter(
-    (attendee) =>
-      attendee.fullName.toLowerCase().includes(searchTerm.toLowerCase()) ||
-      attendee.email.toLowerCase().includes(searchTerm.toLowerCase())
-  );
+  // Search runs on the server so it covers attendees beyond the loaded pages
+  useEffect(() => {
+    const term = searchTerm.trim();
+    if (!term) {
+      setSearchResults([]);
+      return;
+    }
+    const timeout = setTimeout(async () => {
+      try {
+        setSearchResults(await searchAttendees(term, 100));
+      } catch (error) {
+        console.error('Failed to search attendees:', error);
+      }
+    }, 250);
+    return () => clearTimeout(timeout);
+  }, [searchTerm]);
+
+  const filteredAttendees = searchTerm.trim() ? searchResults : attendees;
 
   const chartData = Object.entries(stats).map(([name, value]) => ({
     name,
@@ -162,6 +192,12 @@ const AdminDashboard: React.FC<AdminDashboardProps> = ({ onLogout }) => {
                 value={searchTerm}
                 onChange={(e) => setSearchTerm(e.target.value)}
               />
+              <button onClick={() => exportAttendees('csv')} className="export-button">
+                Export CSV
+              </button>
+              <button onClick={() => exportAttendees('xlsx')} className="export-button">
+                Export XLSX
+              </button>
             </div>
             <div className="attendees-table-container">
               <table className="attendees-table">
@@ -185,6 +221,11 @@ const AdminDashboard: React.FC<AdminDashboardProps> = ({ onLogout }) => {
                 </tbody>
               </table>
             </div>
+            {nextCursor && !searchTerm.trim() && (
+              <button onClick={loadMoreAttendees} className="load-more-button">
+                Load more
+              </button>
+            )}
           </div>
         </div>
       )}
@@ -1,5 +1,5 @@
 import { useState } from 'react';
-import { adminLogin } from '../services/api';
+import { adminLogin, apiErrorOf, describeApiError } from '../services/api';
 import './AdminLogin.css';
 
 interface AdminLoginProps {
@@ -21,8 +21,9 @@ const AdminLogin: React.FC<AdminLoginProps> = ({ onSuccess, onClose }) => {
       const token = await adminLogin(password);
       localStorage.setItem('adminToken', token);
       onSuccess();
-    } catch (err: any) {
-      setError(err.response?.data?.error || 'Invalid password');
+    } catch (err) {
+      const apiError = apiErrorOf(err);
+      setError(apiError && apiError.code !== 'invalid_credentials' ? describeApiError(apiError) : 'Invalid password');
     } finally {
       setLoading(false);
     }
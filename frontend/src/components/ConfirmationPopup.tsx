@@ -9,10 +9,10 @@ const ConfirmationPopup: React.FC<ConfirmationPopupProps> = ({ onClose, attendee
   return (
     <div className="popup-overlay" onClick={onClose}>
       <div className="popup-content" onClick={(e) => e.stopPropagation()}>
-        <div className="popup-icon">✓</div>
-        <h2 className="popup-title">Registration Successful!</h2>
+        <div className="popup-icon">✉</div>
+        <h2 className="popup-title">Check Your Email</h2>
         <p className="popup-message">
-          Thank you, {attendeeName}! You have successfully registered for the event.
+          Thank you, {attendeeName}! We have sent you a confirmation link. Your seat is held until you follow it.
         </p>
         <button className="popup-button" onClick={onClose}>
           Close
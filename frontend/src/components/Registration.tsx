@@ -1,5 +1,5 @@
 import { useState, useEffect } from 'react';
-import { registerAttendee, getAttendeeCount } from '../services/api';
+import { registerAttendee, getAttendeeCount, subscribeAttendeeCount, apiErrorOf, describeApiError } from '../services/api';
 import type { RegisterRequest } from '../types';
 import ConfirmationPopup from './ConfirmationPopup';
 import './Registration.css';
@@ -24,6 +24,8 @@ const Registration: React.FC = () => {
   const [showPopup, setShowPopup] = useState(false);
   const [registeredName, setRegisteredName] = useState('');
   const [error, setError] = useState('');
+  const [fieldErrors, setFieldErrors] = useState<Record<string, string>>({});
+  const [website, setWebsite] = useState('');
 
   useEffect(() => {
     const fetchCount = async () => {
@@ -35,27 +37,48 @@ const Registration: React.FC = () => {
       }
     };
 
-    fetchCount();
-    const interval = setInterval(fetchCount, 3000); // Poll every 3 seconds
+    // Prefer the live stream; fall back to polling if it can't be established
+    let interval: ReturnType<typeof setInterval> | undefined;
+    const unsubscribe = subscribeAttendeeCount(setCount, () => {
+      if (!interval) {
+        fetchCount();
+        interval = setInterval(fetchCount, 3000); // Poll every 3 seconds
+      }
+    });
 
-    return () => clearInterval(interval);
+    return () => {
+      unsubscribe();
+      if (interval) {
+        clearInterval(interval);
+      }
+    };
   }, []);
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
     setError('');
+    setFieldErrors({});
     setLoading(true);
 
     try {
-      await registerAttendee(formData);
+      await registerAttendee({ ...formData, website });
       setRegisteredName(formData.fullName);
       setShowPopup(true);
       setFormData({ fullName: '', email: '', designation: '' });
       // Refresh count
       const newCount = await getAttendeeCount();
       setCount(newCount);
-    } catch (err: any) {
-      setError(err.response?.data?.error || 'Registration failed. Please try again.');
+    } catch (err) {
+      const apiError = apiErrorOf(err);
+      if (apiError?.code === 'email_taken') {
+        setFieldErrors({ email: 'This email is already registered.' });
+      } else if (apiError?.code === 'event_full') {
+        setError('Sorry, the event is full.');
+      } else if (apiError?.fields) {
+        setFieldErrors(apiError.fields);
+      } else {
+        setError(apiError ? describeApiError(apiError) : 'Registration failed. Please try again.');
+      }
     } finally {
       setLoading(false);
     }
@@ -85,6 +108,7 @@ const Registration: React.FC = () => {
                   required
                   placeholder="Enter your full name"
                 />
+                {fieldErrors.fullName && <div className="field-error">{fieldErrors.fullName}</div>}
               </div>
 
               <div className="form-group">
@@ -97,6 +121,7 @@ const Registration: React.FC = () => {
                   required
                   placeholder="Enter your email"
                 />
+                {fieldErrors.email && <div className="field-error">{fieldErrors.email}</div>}
               </div>
 
               <div className="form-group">
@@ -114,6 +139,21 @@ const Registration: React.FC = () => {
                     </option>
                   ))}
                 </select>
+                {fieldErrors.designation && <div className="field-error">{fieldErrors.designation}</div>}
+              </div>
+
+              {/* Honeypot: hidden from people and assistive technology, so only bots fill it in */}
+              <div className="form-honeypot" aria-hidden="true">
+                <label htmlFor="website">Website</label>
+                <input
+                  type="text"
+                  id="website"
+                  name="website"
+                  tabIndex={-1}
+                  autoComplete="off"
+                  value={website}
+                  onChange={(e) => setWebsite(e.target.value)}
+                />
               </div>
 
               {error && <div className="error-message">{error}</div>}
@@ -0,0 +1,78 @@
+import { useEffect, useState } from 'react';
+import { verifyRegistration, apiErrorOf, describeApiError } from '../services/api';
+import './ConfirmationPopup.css';
+
+interface VerifyRegistrationProps {
+  token: string;
+  onClose: () => void;
+}
+
+type VerifyState =
+  | { status: 'verifying' }
+  | { status: 'confirmed' }
+  | { status: 'failed'; title: string; message: string };
+
+// Confirms a registration from the link emailed to the attendee. The link
+// opens the site rather than the API so that mail scanners following links
+// do not confirm registrations on their own.
+const VerifyRegistration: React.FC<VerifyRegistrationProps> = ({ token, onClose }) => {
+  const [state, setState] = useState<VerifyState>({ status: 'verifying' });
+
+  useEffect(() => {
+    let cancelled = false;
+    verifyRegistration(token)
+      .then(() => {
+        if (!cancelled) {
+          setState({ status: 'confirmed' });
+        }
+      })
+      .catch((err) => {
+        if (cancelled) {
+          return;
+        }
+        const apiError = apiErrorOf(err);
+        if (apiError?.code === 'verification_expired') {
+          setState({ status: 'failed', title: 'Link Expired', message: 'Your seat was released. Please register again.' });
+        } else if (apiError?.code === 'not_found') {
+          setState({ status: 'failed', title: 'Invalid Link', message: 'This confirmation link is not valid. Please check the link in your email.' });
+        } else {
+          setState({
+            status: 'failed',
+            title: 'Confirmation Failed',
+            message: apiError ? describeApiError(apiError) : 'Please try again later.',
+          });
+        }
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [token]);
+
+  return (
+    <div className="popup-overlay" onClick={onClose}>
+      <div className="popup-content" onClick={(e) => e.stopPropagation()}>
+        {state.status === 'verifying' && <h2 className="popup-title">Confirming your registration...</h2>}
+        {state.status === 'confirmed' && (
+          <>
+            <div className="popup-icon">✓</div>
+            <h2 className="popup-title">Registration Confirmed!</h2>
+            <p className="popup-message">Your seat is confirmed. See you at the event!</p>
+          </>
+        )}
+        {state.status === 'failed' && (
+          <>
+            <h2 className="popup-title">{state.title}</h2>
+            <p className="popup-message">{state.message}</p>
+          </>
+        )}
+        {state.status !== 'verifying' && (
+          <button className="popup-button" onClick={onClose}>
+            Close
+          </button>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default VerifyRegistration;
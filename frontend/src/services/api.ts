@@ -1,7 +1,7 @@
 import axios from 'axios';
-import type { Attendee, SessionWithSpeaker, Speaker, RegisterRequest, Stats } from '../types';
+import type { ApiError, Attendee, AttendeePage, AttendeeQuery, Session, SessionWithSpeaker, Speaker, RegisterRequest, RegistrationChallenge, Stats, FeedEvent } from '../types';
 
-const API_URL = import.meta.env.VITE_API_URL || '/api';
+const API_URL = import.meta.env.VITE_API_URL || '/api/v1';
 
 const api = axios.create({
   baseURL: API_URL,
@@ -28,6 +28,24 @@ api.interceptors.response.use(
   }
 );
 
+// Returns the error envelope of a failed request, or undefined for network
+// errors and responses that are not from the API.
+export const apiErrorOf = (error: unknown): ApiError | undefined => {
+  if (!axios.isAxiosError(error)) {
+    return undefined;
+  }
+  const data = error.response?.data as Partial<ApiError> | undefined;
+  if (data && typeof data.code === 'string' && typeof data.message === 'string') {
+    return data as ApiError;
+  }
+  return undefined;
+};
+
+// Formats an error for display, including the reference ID of internal
+// errors so users can quote it when reporting a problem.
+export const describeApiError = (error: ApiError): string =>
+  error.requestId ? `${error.message} (reference: ${error.requestId})` : error.message;
+
 export const getSessions = async (): Promise<SessionWithSpeaker[]> => {
   const response = await api.get<SessionWithSpeaker[]>('/sessions');
   return Array.isArray(response.data) ? response.data : [];
@@ -38,8 +56,42 @@ export const getSpeakers = async (): Promise<Speaker[]> => {
   return Array.isArray(response.data) ? response.data : [];
 };
 
+const leadingZeroBits = (bytes: Uint8Array): number => {
+  let bits = 0;
+  for (const byte of bytes) {
+    if (byte !== 0) {
+      return bits + Math.clz32(byte) - 24;
+    }
+    bits += 8;
+  }
+  return bits;
+};
+
+// Finds a nonce such that SHA-256 of the email and nonce starts with
+// difficulty zero bits, as described by GET /register/challenge.
+const solveProofOfWork = async (email: string, difficulty: number): Promise<string> => {
+  const encoder = new TextEncoder();
+  const prefix = `event-registration:${email.trim().toLowerCase()}:`;
+  for (let nonce = 0; ; nonce++) {
+    const digest = await crypto.subtle.digest('SHA-256', encoder.encode(prefix + nonce));
+    if (leadingZeroBits(new Uint8Array(digest)) >= difficulty) {
+      return String(nonce);
+    }
+  }
+};
+
+// Solves the server's registration challenge, if any, before registering
 export const registerAttendee = async (data: RegisterRequest): Promise<void> => {
-  await api.post('/register', data);
+  const { data: challenge } = await api.get<RegistrationChallenge>('/register/challenge');
+  const body: RegisterRequest = { ...data };
+  if (challenge.type === 'proof-of-work') {
+    body.challenge = await solveProofOfWork(data.email, challenge.difficulty ?? 0);
+  }
+  await api.post('/register', body);
+};
+
+export const verifyRegistration = async (token: string): Promise<void> => {
+  await api.post('/register/verify', { token });
 };
 
 export const getAttendeeCount = async (): Promise<number> => {
@@ -47,14 +99,69 @@ export const getAttendeeCount = async (): Promise<number> => {
   return response.data.count;
 };
 
+// Subscribes to live attendee count updates over Server-Sent Events.
+// Returns a function that closes the stream.
+export const subscribeAttendeeCount = (
+  onCount: (count: number) => void,
+  onError?: () => void,
+): (() => void) => {
+  const source = new EventSource(`${API_URL}/attendees/count/stream`);
+  source.addEventListener('count', (event) => {
+    const { count } = JSON.parse((event as MessageEvent).data) as { count: number };
+    onCount(count);
+  });
+  if (onError) {
+    source.onerror = onError;
+  }
+  return () => source.close();
+};
+
 export const adminLogin = async (password: string): Promise<string> => {
   const response = await api.post<{ token: string }>('/admin/login', { password });
   return response.data.token;
 };
 
-export const getAttendees = async (): Promise<Attendee[]> => {
-  const response = await api.get<Attendee[]>('/admin/attendees');
-  return response.data;
+// Opens the authenticated admin WebSocket feed of registrations and agenda
+// edits. Returns a function that closes the connection.
+export const subscribeAdminFeed = (onEvent: (event: FeedEvent) => void): (() => void) => {
+  const token = localStorage.getItem('adminToken') || '';
+  const base = new URL(`${API_URL}/admin/feed`, window.location.href);
+  base.protocol = base.protocol === 'https:' ? 'wss:' : 'ws:';
+  base.searchParams.set('token', token);
+
+  const socket = new WebSocket(base.toString());
+  socket.onmessage = (message) => onEvent(JSON.parse(message.data) as FeedEvent);
+  return () => socket.close();
+};
+
+export const getAttendees = async (params: AttendeeQuery = {}): Promise<AttendeePage> => {
+  const response = await api.get<AttendeePage>('/admin/attendees', { params });
+  return {
+    attendees: response.data.attendees ?? [],
+    nextCursor: response.data.nextCursor,
+  };
+};
+
+export const searchAttendees = async (q: string, limit = 20): Promise<Attendee[]> => {
+  const response = await api.get<AttendeePage>('/admin/attendees/search', { params: { q, limit } });
+  return response.data.attendees ?? [];
+};
+
+// Downloads the attendee list, honouring the same filters as getAttendees.
+export const exportAttendees = async (
+  format: 'csv' | 'xlsx',
+  params: Omit<AttendeeQuery, 'limit' | 'cursor'> & { columns?: string; tz?: string } = {},
+): Promise<void> => {
+  const response = await api.get<Blob>('/admin/attendees/export', {
+    params: { format, tz: Intl.DateTimeFormat().resolvedOptions().timeZone, ...params },
+    responseType: 'blob',
+  });
+  const url = URL.createObjectURL(response.data);
+  const link = document.createElement('a');
+  link.href = url;
+  link.download = `attendees.${format}`;
+  link.click();
+  URL.revokeObjectURL(url);
 };
 
 export const getStats = async (): Promise<Stats> => {
@@ -62,13 +169,14 @@ export const getStats = async (): Promise<Stats> => {
   return response.data;
 };
 
-export const addUpdateSpeaker = async (speaker: Partial<Speaker> & { id?: string }): Promise<Speaker> => {
-  const response = await api.post<Speaker>('/admin/speakers', speaker);
+// The admin endpoints reject unknown fields, so only the writable fields are sent
+export const addUpdateSpeaker = async ({ id, name, bio, photoURL }: Partial<Speaker>): Promise<Speaker> => {
+  const response = await api.post<Speaker>('/admin/speakers', { id, name, bio, photoURL });
   return response.data;
 };
 
-export const addUpdateSession = async (session: Partial<SessionWithSpeaker> & { id?: string }): Promise<SessionWithSpeaker> => {
-  const response = await api.post<SessionWithSpeaker>('/admin/sessions', session);
+export const addUpdateSession = async ({ id, title, description, time, speakerId }: Partial<Session>): Promise<SessionWithSpeaker> => {
+  const response = await api.post<SessionWithSpeaker>('/admin/sessions', { id, title, description, time, speakerId });
   return response.data;
 };
 
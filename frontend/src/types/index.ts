@@ -3,9 +3,26 @@ export interface Attendee {
   fullName: string;
   email: string;
   designation: string;
+  status: string;
   createdAt: string;
 }
 
+export interface AttendeePage {
+  attendees: Attendee[];
+  nextCursor?: string;
+}
+
+export interface AttendeeQuery {
+  limit?: number;
+  sort?: 'createdAt' | 'fullName';
+  order?: 'asc' | 'desc';
+  designation?: string;
+  status?: string;
+  from?: string;
+  to?: string;
+  cursor?: string;
+}
+
 export interface Speaker {
   id: string;
   name: string;
@@ -29,9 +46,34 @@ export interface RegisterRequest {
   fullName: string;
   email: string;
   designation: string;
+  // Honeypot: hidden from people, so only bots fill it in
+  website?: string;
+  // Solution to the registration challenge, when one is required
+  challenge?: string;
+}
+
+export interface RegistrationChallenge {
+  type: string; // 'none', 'proof-of-work', or another verifier's type
+  difficulty?: number;
+}
+
+// Body of every error response. `fields` maps a request field to what is
+// wrong with it and is only present when validation failed; `requestId` is
+// set on internal errors so a report can be matched to the server log.
+export interface ApiError {
+  code: string;
+  message: string;
+  fields?: Record<string, string>;
+  requestId?: string;
 }
 
 export interface Stats {
   [designation: string]: number;
 }
 
+
+export interface FeedEvent {
+  type: 'registration' | 'cancellation' | 'registration_expired' | 'check_in' | 'speaker_saved' | 'session_saved';
+  data?: unknown;
+  time: string;
+}